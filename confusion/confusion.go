@@ -0,0 +1,174 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package confusion provides a confusion matrix for recording the
+classification performance of a network, e.g., from the argmax of its
+output layer activations, with accuracy and per-class precision and
+recall statistics, and conversion to an etable.Table for logging.
+*/
+package confusion
+
+import (
+	"fmt"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// Matrix is a confusion matrix, with rows for the actual (target) class
+// and columns for the predicted (output) class, with each cell containing
+// the number of times that combination occurred.
+type Matrix struct {
+	Counts etensor.Float32 `view:"no-inline" desc:"[actual][predicted] counts of each combination of actual and predicted class"`
+	Labels []string        `desc:"optional names of each class, used for the Table and SaveCSV output"`
+}
+
+// Init initializes the matrix for given number of classes, setting all counts to 0
+func (cm *Matrix) Init(nClasses int) {
+	cm.Counts.SetShape([]int{nClasses, nClasses}, nil, []string{"Actual", "Predicted"})
+	cm.Reset()
+}
+
+// Reset sets all the counts to 0, e.g., at the start of a new test epoch
+func (cm *Matrix) Reset() {
+	for i := range cm.Counts.Values {
+		cm.Counts.Values[i] = 0
+	}
+}
+
+// N returns the number of classes
+func (cm *Matrix) N() int {
+	if cm.Counts.NumDims() == 0 {
+		return 0
+	}
+	return cm.Counts.Dim(0)
+}
+
+// Inc increments the count for given predicted and actual class.
+// Out-of-range classes are ignored.
+func (cm *Matrix) Inc(predicted, actual int) {
+	n := cm.N()
+	if predicted < 0 || predicted >= n || actual < 0 || actual >= n {
+		return
+	}
+	cm.Counts.Values[actual*n+predicted]++
+}
+
+// Matrix returns the counts, as [actual][predicted] tensor
+func (cm *Matrix) Matrix() *etensor.Float32 {
+	return &cm.Counts
+}
+
+// Probs returns a new tensor with the row-normalized counts: for each actual
+// class, the proportion of times each class was predicted.
+func (cm *Matrix) Probs() *etensor.Float32 {
+	n := cm.N()
+	pr := etensor.NewFloat32([]int{n, n}, nil, []string{"Actual", "Predicted"})
+	for a := 0; a < n; a++ {
+		row := cm.Counts.Values[a*n : (a+1)*n]
+		sum := float32(0)
+		for _, v := range row {
+			sum += v
+		}
+		if sum == 0 {
+			continue
+		}
+		for p, v := range row {
+			pr.Values[a*n+p] = v / sum
+		}
+	}
+	return pr
+}
+
+// Total returns the total count across all cells
+func (cm *Matrix) Total() float32 {
+	sum := float32(0)
+	for _, v := range cm.Counts.Values {
+		sum += v
+	}
+	return sum
+}
+
+// Accuracy returns the overall proportion of correct classifications
+// (sum of the diagonal over the total count), 0 if nothing has been counted.
+func (cm *Matrix) Accuracy() float32 {
+	tot := cm.Total()
+	if tot == 0 {
+		return 0
+	}
+	n := cm.N()
+	cor := float32(0)
+	for c := 0; c < n; c++ {
+		cor += cm.Counts.Values[c*n+c]
+	}
+	return cor / tot
+}
+
+// Precision returns the proportion of predictions of given class that were
+// correct (0 if the class was never predicted).
+func (cm *Matrix) Precision(cls int) float32 {
+	n := cm.N()
+	sum := float32(0)
+	for a := 0; a < n; a++ {
+		sum += cm.Counts.Values[a*n+cls]
+	}
+	if sum == 0 {
+		return 0
+	}
+	return cm.Counts.Values[cls*n+cls] / sum
+}
+
+// Recall returns the proportion of items of given actual class that were
+// correctly predicted (0 if there were no such items).
+func (cm *Matrix) Recall(cls int) float32 {
+	n := cm.N()
+	sum := float32(0)
+	for p := 0; p < n; p++ {
+		sum += cm.Counts.Values[cls*n+p]
+	}
+	if sum == 0 {
+		return 0
+	}
+	return cm.Counts.Values[cls*n+cls] / sum
+}
+
+// Label returns the label for given class, using Labels if set,
+// otherwise the class number
+func (cm *Matrix) Label(cls int) string {
+	if cls < len(cm.Labels) {
+		return cm.Labels[cls]
+	}
+	return fmt.Sprintf("%d", cls)
+}
+
+// Table returns an etable.Table with one row per actual class, with
+// columns for the class label (Actual), the count for each predicted class
+// (named by the class label), and the Precision and Recall for that class.
+func (cm *Matrix) Table() *etable.Table {
+	n := cm.N()
+	sc := etable.Schema{{Name: "Actual", Type: etensor.STRING}}
+	for p := 0; p < n; p++ {
+		sc = append(sc, etable.Column{Name: cm.Label(p), Type: etensor.FLOAT32})
+	}
+	sc = append(sc, etable.Column{Name: "Precision", Type: etensor.FLOAT32})
+	sc = append(sc, etable.Column{Name: "Recall", Type: etensor.FLOAT32})
+	dt := etable.New(sc, n)
+	dt.SetMetaData("name", "Confusion")
+	for a := 0; a < n; a++ {
+		dt.SetCellStringIdx(0, a, cm.Label(a))
+		for p := 0; p < n; p++ {
+			dt.SetCellFloatIdx(1+p, a, float64(cm.Counts.Values[a*n+p]))
+		}
+		dt.SetCellFloatIdx(1+n, a, float64(cm.Precision(a)))
+		dt.SetCellFloatIdx(2+n, a, float64(cm.Recall(a)))
+	}
+	return dt
+}
+
+// SaveCSV saves the Table version of the matrix to given file, in CSV format
+func (cm *Matrix) SaveCSV(fname gi.FileName) error {
+	return cm.Table().SaveCSV(fname, etable.Comma, etable.Headers)
+}
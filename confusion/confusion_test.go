@@ -0,0 +1,45 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package confusion
+
+import (
+	"testing"
+)
+
+func TestMatrix(t *testing.T) {
+	cm := &Matrix{}
+	cm.Init(3)
+	cm.Labels = []string{"A", "B", "C"}
+	cm.Inc(0, 0)
+	cm.Inc(0, 0)
+	cm.Inc(1, 0)
+	cm.Inc(1, 1)
+	cm.Inc(2, 2)
+	cm.Inc(3, 2) // ignored
+	if cm.Total() != 5 {
+		t.Errorf("total: %v", cm.Total())
+	}
+	if acc := cm.Accuracy(); acc != 0.8 {
+		t.Errorf("accuracy: %v", acc)
+	}
+	if pr := cm.Precision(1); pr != 0.5 {
+		t.Errorf("precision B: %v", pr)
+	}
+	if rc := cm.Recall(0); rc != float32(2)/3 {
+		t.Errorf("recall A: %v", rc)
+	}
+	pr := cm.Probs()
+	if pr.Value([]int{0, 1}) != float32(1)/3 || pr.Value([]int{2, 2}) != 1 {
+		t.Errorf("probs: %v", pr)
+	}
+	dt := cm.Table()
+	if dt.Rows != 3 || dt.CellFloat("A", 0) != 2 || dt.CellString("Actual", 1) != "B" || dt.CellFloat("Recall", 1) != 1 {
+		t.Errorf("table:\n%v", dt)
+	}
+	cm.Reset()
+	if cm.Total() != 0 || cm.Accuracy() != 0 {
+		t.Errorf("reset failed")
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package decoder provides simple decoders for reading out the information
+encoded in layer activation patterns, e.g., after training, by learning a
+mapping from activation vectors to target (label) vectors.
+
+Activation and label data are passed as etensor.Float32 tensors where the
+outer-most dimension is the row (pattern), as in an etable.Table column,
+and all the remaining dimensions are flattened.  Labels are typically
+one-hot (localist) class codes, for which Accuracy and CrossValAccuracy
+compute the proportion of rows where the maximum predicted value is
+at the index of the maximum label value.
+*/
+package decoder
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/emer/etable/etensor"
+)
+
+// Decoder is the interface for a decoder that is trained on a set of
+// activation patterns and corresponding labels, and then predicts
+// labels for new activation patterns.
+type Decoder interface {
+	// Train trains the decoder on given activations and labels,
+	// with corresponding rows in the outer-most dimension.
+	Train(acts, labels *etensor.Float32) error
+
+	// Predict returns the predicted labels for given activations,
+	// as a [rows, labels] tensor.
+	Predict(acts *etensor.Float32) (*etensor.Float32, error)
+}
+
+// Rows returns the number of rows (outer-most dimension) in given tensor,
+// and the number of values per row.
+func Rows(tsr *etensor.Float32) (rows, n int) {
+	if tsr.NumDims() == 0 || tsr.Dim(0) == 0 {
+		return 0, 0
+	}
+	rows = tsr.Dim(0)
+	return rows, tsr.Len() / rows
+}
+
+// Row returns the values of given row of given tensor, which
+// must have been obtained with a valid row index.
+func Row(tsr *etensor.Float32, row int) []float32 {
+	_, n := Rows(tsr)
+	return tsr.Values[row*n : (row+1)*n]
+}
+
+// CheckRows returns an error if the two tensors do not have the same,
+// non-zero number of rows.
+func CheckRows(acts, labels *etensor.Float32) error {
+	ar, _ := Rows(acts)
+	lr, _ := Rows(labels)
+	if ar == 0 || ar != lr {
+		return fmt.Errorf("decoder: activations have %d rows and labels have %d -- must be same and > 0", ar, lr)
+	}
+	return nil
+}
+
+// MaxIdx returns the index of the maximum value in given slice
+func MaxIdx(vals []float32) int {
+	mi := 0
+	for i, v := range vals {
+		if v > vals[mi] {
+			mi = i
+		}
+	}
+	return mi
+}
+
+// Accuracy returns the proportion of rows in which the index of the maximum
+// predicted value is the same as that of the maximum label value.
+func Accuracy(preds, labels *etensor.Float32) (float64, error) {
+	if err := CheckRows(preds, labels); err != nil {
+		return 0, err
+	}
+	rows, _ := Rows(labels)
+	ncor := 0
+	for r := 0; r < rows; r++ {
+		if MaxIdx(Row(preds, r)) == MaxIdx(Row(labels, r)) {
+			ncor++
+		}
+	}
+	return float64(ncor) / float64(rows), nil
+}
+
+// CrossValAccuracy computes the k-fold cross-validated Accuracy of given decoder
+// on given activations and labels: the rows are randomly permuted and divided into
+// k folds, and for each fold the decoder is trained on all the other folds and
+// tested on that fold.  Returns the overall proportion correct across all folds.
+// Uses given rng for the permutation, or a new one seeded from the standard
+// math/rand source if nil.
+func CrossValAccuracy(dec Decoder, acts, labels *etensor.Float32, k int, rng *rand.Rand) (float64, error) {
+	if err := CheckRows(acts, labels); err != nil {
+		return 0, err
+	}
+	rows, _ := Rows(acts)
+	if k < 2 || k > rows {
+		return 0, fmt.Errorf("decoder.CrossValAccuracy: k: %d must be between 2 and number of rows: %d", k, rows)
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	perm := rng.Perm(rows)
+	ncor := 0.0
+	for f := 0; f < k; f++ {
+		st := f * rows / k
+		ed := (f + 1) * rows / k
+		var trn, tst []int
+		trn = append(trn, perm[:st]...)
+		trn = append(trn, perm[ed:]...)
+		tst = perm[st:ed]
+		if err := dec.Train(SelectRows(acts, trn), SelectRows(labels, trn)); err != nil {
+			return 0, err
+		}
+		preds, err := dec.Predict(SelectRows(acts, tst))
+		if err != nil {
+			return 0, err
+		}
+		acc, err := Accuracy(preds, SelectRows(labels, tst))
+		if err != nil {
+			return 0, err
+		}
+		ncor += acc * float64(len(tst))
+	}
+	return ncor / float64(rows), nil
+}
+
+// SelectRows returns a new tensor with given rows from given tensor
+func SelectRows(tsr *etensor.Float32, rows []int) *etensor.Float32 {
+	shp := append([]int{len(rows)}, tsr.Shapes()[1:]...)
+	nt := etensor.NewFloat32(shp, nil, nil)
+	for i, r := range rows {
+		copy(Row(nt, i), Row(tsr, r))
+	}
+	return nt
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package decoder
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+// noisyData returns rows of noisy versions of ncat random binary prototypes,
+// with one-hot labels for the category
+func noisyData(rows, ncat, nin int) (acts, labels *etensor.Float32) {
+	protos := make([][]float32, ncat)
+	for c := range protos {
+		protos[c] = make([]float32, nin)
+		for i := range protos[c] {
+			if rand.Float32() < .3 {
+				protos[c][i] = 1
+			}
+		}
+	}
+	acts = etensor.NewFloat32([]int{rows, 5, nin / 5}, nil, nil)
+	labels = etensor.NewFloat32([]int{rows, ncat}, nil, nil)
+	for r := 0; r < rows; r++ {
+		c := r % ncat
+		ar := Row(acts, r)
+		for i, v := range protos[c] {
+			ar[i] = v + 0.3*float32(rand.NormFloat64())
+		}
+		Row(labels, r)[c] = 1
+	}
+	return
+}
+
+func TestDecoders(t *testing.T) {
+	rand.Seed(1)
+	acts, labels := noisyData(100, 4, 40)
+	decs := map[string]Decoder{"Linear": NewLinear(), "KNN": NewKNN()}
+	for nm, dec := range decs {
+		acc, err := CrossValAccuracy(dec, acts, labels, 5, rand.New(rand.NewSource(2)))
+		if err != nil {
+			t.Fatal(nm, err)
+		}
+		if acc < 0.8 { // chance is .25
+			t.Errorf("%v cross-validated accuracy: %v is too low", nm, acc)
+		}
+		// same folds from the same seed
+		acc2, _ := CrossValAccuracy(dec, acts, labels, 5, rand.New(rand.NewSource(2)))
+		if acc2 != acc {
+			t.Errorf("%v cross-validated accuracy not reproducible: %v vs. %v", nm, acc, acc2)
+		}
+		if _, err := CrossValAccuracy(dec, acts, labels, 5, nil); err != nil {
+			t.Errorf("%v with nil rng: %v", nm, err)
+		}
+	}
+	if _, err := CrossValAccuracy(NewLinear(), acts, labels, 1, nil); err == nil {
+		t.Errorf("expected error for k = 1")
+	}
+	ln := NewLinear()
+	if err := ln.Train(acts, labels); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ln.Predict(etensor.NewFloat32([]int{2, 3}, nil, nil)); err == nil {
+		t.Errorf("expected error for wrong number of inputs")
+	}
+	kd := &KNN{}
+	if err := kd.Train(acts, labels); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []int{0, -1} {
+		kd.K = k
+		if _, err := kd.Predict(acts); err == nil {
+			t.Errorf("expected error for K = %d", k)
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	a := []float64{0, 2, 1, 1}
+	b := []float64{4, 3}
+	if err := Solve(a, b, 2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if b[0] != 1 || b[1] != 2 {
+		t.Errorf("solution: %v", b)
+	}
+	if err := Solve([]float64{1, 1, 1, 1}, []float64{1, 1}, 2, 1); err == nil {
+		t.Errorf("expected singular matrix error")
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package decoder
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+)
+
+// KNN is a k-nearest-neighbor decoder that predicts the labels for an
+// activation pattern as the average of the labels of the K training patterns
+// that are closest to it in terms of cosine distance (1 - cosine).
+// For one-hot labels, this is the proportion of votes for each label.
+type KNN struct {
+	K      int              `def:"5" desc:"number of nearest neighbors to average over"`
+	Acts   *etensor.Float32 `view:"-" desc:"training activations, stored by Train"`
+	Labels *etensor.Float32 `view:"-" desc:"training labels, stored by Train"`
+}
+
+// NewKNN returns a new KNN decoder with default parameters
+func NewKNN() *KNN {
+	kd := &KNN{}
+	kd.Defaults()
+	return kd
+}
+
+// Defaults sets default parameter values
+func (kd *KNN) Defaults() {
+	kd.K = 5
+}
+
+// Train stores the given training activations and labels
+func (kd *KNN) Train(acts, labels *etensor.Float32) error {
+	if err := CheckRows(acts, labels); err != nil {
+		return err
+	}
+	kd.Acts = acts
+	kd.Labels = labels
+	return nil
+}
+
+// Predict returns the predicted labels for given activations.
+// Returns an error if not trained, or if K < 1.
+func (kd *KNN) Predict(acts *etensor.Float32) (*etensor.Float32, error) {
+	if kd.K < 1 {
+		return nil, fmt.Errorf("decoder.KNN Predict: K: %d must be > 0 -- call Defaults to initialize", kd.K)
+	}
+	if kd.Acts == nil {
+		return nil, fmt.Errorf("decoder.KNN Predict: not trained")
+	}
+	rows, nin := Rows(acts)
+	trows, tnin := Rows(kd.Acts)
+	if nin != tnin {
+		return nil, fmt.Errorf("decoder.KNN Predict: activations have %d values per row instead of %d", nin, tnin)
+	}
+	_, nout := Rows(kd.Labels)
+	k := kd.K
+	if k > trows {
+		k = trows
+	}
+	preds := etensor.NewFloat32([]int{rows, nout}, nil, nil)
+	idxs := make([]int, trows)
+	dists := make([]float32, trows)
+	for r := 0; r < rows; r++ {
+		ar := Row(acts, r)
+		for t := 0; t < trows; t++ {
+			idxs[t] = t
+			dists[t] = 1 - metric.Cosine32(ar, Row(kd.Acts, t))
+		}
+		sort.SliceStable(idxs, func(i, j int) bool {
+			return dists[idxs[i]] < dists[idxs[j]]
+		})
+		pr := Row(preds, r)
+		for _, t := range idxs[:k] {
+			for o, v := range Row(kd.Labels, t) {
+				pr[o] += v / float32(k)
+			}
+		}
+	}
+	return preds, nil
+}
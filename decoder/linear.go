@@ -0,0 +1,151 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package decoder
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/emer/etable/etensor"
+)
+
+// Linear is a linear decoder that fits the least-squares linear mapping
+// (with a bias term) from activations to labels, with optional ridge
+// (L2) regularization, by solving the normal equations.
+type Linear struct {
+	Lambda  float64   `def:"0.001" desc:"ridge regularization strength, which also keeps the solution well-defined when there are more units than patterns, or units that are always inactive"`
+	NIn     int       `inactive:"+" desc:"number of input (activation) values, set by Train"`
+	NOut    int       `inactive:"+" desc:"number of output (label) values, set by Train"`
+	Weights []float64 `view:"-" desc:"[NIn+1][NOut] learned weights, with the bias weights in the last row"`
+}
+
+// NewLinear returns a new Linear decoder with default parameters
+func NewLinear() *Linear {
+	ld := &Linear{}
+	ld.Defaults()
+	return ld
+}
+
+// Defaults sets default parameter values
+func (ld *Linear) Defaults() {
+	ld.Lambda = 0.001
+}
+
+// Train fits the weights to given activations and labels
+func (ld *Linear) Train(acts, labels *etensor.Float32) error {
+	if err := CheckRows(acts, labels); err != nil {
+		return err
+	}
+	rows, nin := Rows(acts)
+	_, nout := Rows(labels)
+	ld.NIn = nin
+	ld.NOut = nout
+	ni := nin + 1 // plus bias
+	xtx := make([]float64, ni*ni)
+	xty := make([]float64, ni*nout)
+	x := make([]float64, ni)
+	for r := 0; r < rows; r++ {
+		for i, v := range Row(acts, r) {
+			x[i] = float64(v)
+		}
+		x[nin] = 1
+		y := Row(labels, r)
+		for i := 0; i < ni; i++ {
+			xi := x[i]
+			if xi == 0 {
+				continue
+			}
+			for j := 0; j < ni; j++ {
+				xtx[i*ni+j] += xi * x[j]
+			}
+			for o := 0; o < nout; o++ {
+				xty[i*nout+o] += xi * float64(y[o])
+			}
+		}
+	}
+	for i := 0; i < nin; i++ { // not regularizing the bias
+		xtx[i*ni+i] += ld.Lambda
+	}
+	if err := Solve(xtx, xty, ni, nout); err != nil {
+		return fmt.Errorf("decoder.Linear Train: %v -- try increasing Lambda", err)
+	}
+	ld.Weights = xty
+	return nil
+}
+
+// Predict returns the predicted labels for given activations
+func (ld *Linear) Predict(acts *etensor.Float32) (*etensor.Float32, error) {
+	rows, nin := Rows(acts)
+	if ld.Weights == nil || nin != ld.NIn {
+		return nil, fmt.Errorf("decoder.Linear Predict: not trained, or activations have %d values per row instead of %d", nin, ld.NIn)
+	}
+	nout := ld.NOut
+	preds := etensor.NewFloat32([]int{rows, nout}, nil, nil)
+	bias := ld.Weights[nin*nout:]
+	for r := 0; r < rows; r++ {
+		pr := Row(preds, r)
+		for o := 0; o < nout; o++ {
+			pr[o] = float32(bias[o])
+		}
+		for i, v := range Row(acts, r) {
+			if v == 0 {
+				continue
+			}
+			wi := ld.Weights[i*nout : (i+1)*nout]
+			for o := range pr {
+				pr[o] += v * float32(wi[o])
+			}
+		}
+	}
+	return preds, nil
+}
+
+// Solve solves the linear system A X = B for X, using Gaussian elimination
+// with partial pivoting, where A is n x n and B is n x m, both in row-major order.
+// A is destroyed and B is replaced with the solution X.
+// Returns an error if A is singular.
+func Solve(a, b []float64, n, m int) error {
+	for c := 0; c < n; c++ {
+		piv := c
+		for r := c + 1; r < n; r++ {
+			if math.Abs(a[r*n+c]) > math.Abs(a[piv*n+c]) {
+				piv = r
+			}
+		}
+		if math.Abs(a[piv*n+c]) < 1.0e-12 {
+			return fmt.Errorf("singular matrix")
+		}
+		if piv != c {
+			for j := 0; j < n; j++ {
+				a[c*n+j], a[piv*n+j] = a[piv*n+j], a[c*n+j]
+			}
+			for j := 0; j < m; j++ {
+				b[c*m+j], b[piv*m+j] = b[piv*m+j], b[c*m+j]
+			}
+		}
+		for r := 0; r < n; r++ {
+			if r == c {
+				continue
+			}
+			f := a[r*n+c] / a[c*n+c]
+			if f == 0 {
+				continue
+			}
+			for j := c; j < n; j++ {
+				a[r*n+j] -= f * a[c*n+j]
+			}
+			for j := 0; j < m; j++ {
+				b[r*m+j] -= f * b[c*m+j]
+			}
+		}
+	}
+	for r := 0; r < n; r++ {
+		d := a[r*n+r]
+		for j := 0; j < m; j++ {
+			b[r*m+j] /= d
+		}
+	}
+	return nil
+}
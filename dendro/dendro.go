@@ -0,0 +1,175 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dendro
+
+import (
+	"github.com/emer/etable/clust"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/simat"
+	"github.com/goki/ki/kit"
+)
+
+// Linkage is the method for computing the distance between two clusters
+type Linkage int32
+
+//go:generate stringer -type=Linkage
+
+var KiT_Linkage = kit.Enums.AddEnum(LinkageN, kit.NotBitFlag, nil)
+
+func (ev Linkage) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *Linkage) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// The linkage methods
+const (
+	// Single uses the minimum distance between any two members of the clusters
+	Single Linkage = iota
+
+	// Complete uses the maximum distance between any two members of the clusters
+	Complete
+
+	// Average uses the average distance between all members of the clusters (UPGMA)
+	Average
+
+	// Ward uses the increase in total within-cluster sum of squared distances
+	// resulting from merging the clusters, which assumes a Euclidean distance matrix
+	Ward
+
+	LinkageN
+)
+
+// DistFunc returns the clust.DistFunc for given linkage method
+func (lk Linkage) DistFunc() clust.DistFunc {
+	switch lk {
+	case Single:
+		return clust.MinDist
+	case Complete:
+		return clust.MaxDist
+	case Ward:
+		return WardDist
+	}
+	return clust.AvgDist
+}
+
+// Merge records one step of the agglomerative clustering process
+type Merge struct {
+	A    int     `desc:"index of the first cluster merged: leaves are 0..NLeaves-1 and the cluster created by merge i has index NLeaves+i"`
+	B    int     `desc:"index of the second cluster merged, using same indexing as A"`
+	Dist float64 `desc:"distance between the two clusters when merged -- the height of the merge in the dendrogram"`
+	N    int     `desc:"number of leaves in the merged cluster"`
+}
+
+// ClusterTree does hierarchical agglomerative clustering of the given square
+// similarity (distance) matrix using given linkage method, returning the root
+// node of the resulting binary cluster tree.  labels are the names of the
+// items (rows) in the matrix, and can be nil.  Larger values in the matrix
+// must indicate greater distance.
+func ClusterTree(simMat etensor.Tensor, labels []string, lk Linkage) *clust.Node {
+	smat := SimMat(simMat, labels)
+	root := clust.Glom(smat, lk.DistFunc())
+	if len(root.Kids) == 1 { // Glom leaves final cluster as only kid of root
+		return root.Kids[0]
+	}
+	return root
+}
+
+// SimMat returns a simat.SimMat with a Float64 copy of given square
+// similarity matrix and given labels for rows and columns, as used by clust.
+func SimMat(simMat etensor.Tensor, labels []string) *simat.SimMat {
+	n := simMat.Dim(0)
+	mat := etensor.NewFloat64([]int{n, n}, nil, nil)
+	for i := range mat.Values {
+		mat.Values[i] = simMat.FloatVal1D(i)
+	}
+	smat := &simat.SimMat{Mat: mat}
+	if labels != nil {
+		smat.Rows = labels
+		smat.Cols = labels
+	}
+	return smat
+}
+
+// Leaves returns the indexes of the leaves (items) of given cluster tree
+// in the order in which they appear in the dendrogram.
+func Leaves(nd *clust.Node) []int {
+	if nd.IsLeaf() {
+		return []int{nd.Idx}
+	}
+	var lv []int
+	for _, kn := range nd.Kids {
+		lv = append(lv, Leaves(kn)...)
+	}
+	return lv
+}
+
+// LeafLabels returns the labels of the leaves of given cluster tree in
+// dendrogram order.
+func LeafLabels(nd *clust.Node, labels []string) []string {
+	lv := Leaves(nd)
+	lbls := make([]string, len(lv))
+	for i, li := range lv {
+		lbls[i] = labels[li]
+	}
+	return lbls
+}
+
+// Merges returns the sequence of merges in given cluster tree, ordered such
+// that the merges creating each cluster come before the merge that uses it,
+// which together with Leaves provides all the information needed to render
+// a dendrogram (this is the same form as the linkage matrix in scipy).
+func Merges(nd *clust.Node) []Merge {
+	nl := len(Leaves(nd))
+	var mgs []Merge
+	merges(nd, nl, &mgs)
+	return mgs
+}
+
+// merges recursively adds the merges for given node to the list after
+// those of its kids, returning the cluster index of the node, and its number of leaves
+func merges(nd *clust.Node, nl int, mgs *[]Merge) (int, int) {
+	if nd.IsLeaf() {
+		return nd.Idx, 1
+	}
+	ci := -1
+	n := 0
+	for _, kd := range nd.Kids {
+		ki, kn := merges(kd, nl, mgs)
+		if ci < 0 {
+			ci = ki
+			n = kn
+			continue
+		}
+		n += kn
+		*mgs = append(*mgs, Merge{A: ci, B: ki, Dist: nd.Dist, N: n})
+		ci = nl + len(*mgs) - 1
+	}
+	return ci, n
+}
+
+// WardDist is the Ward linkage distance function for comparing two clusters
+// a and b, given by their list of indexes: the increase in the total within-cluster
+// sum of squares that would result from merging them.  The sum of squares for a
+// cluster is computed from the distances as sum(d_ij^2) / n over all pairs i < j,
+// which is exact for Euclidean distances.
+// ntot is total number of nodes, and smat is the square similarity matrix [ntot x ntot].
+func WardDist(aix, bix []int, ntot int, maxd float64, smat []float64) float64 {
+	ssa := sumSq(aix, aix, ntot, smat) / 2
+	ssb := sumSq(bix, bix, ntot, smat) / 2
+	ssab := sumSq(aix, bix, ntot, smat)
+	na := float64(len(aix))
+	nb := float64(len(bix))
+	return (ssa+ssb+ssab)/(na+nb) - ssa/na - ssb/nb
+}
+
+// sumSq returns the sum of squared distances between all items in aix and bix
+func sumSq(aix, bix []int, ntot int, smat []float64) float64 {
+	ss := 0.0
+	for _, ai := range aix {
+		for _, bi := range bix {
+			d := smat[ai*ntot+bi]
+			ss += d * d
+		}
+	}
+	return ss
+}
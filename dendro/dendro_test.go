@@ -0,0 +1,67 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dendro
+
+import (
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+func TestClusterTree(t *testing.T) {
+	// two tight pairs: a,b and c,d, far apart from each other
+	sm := etensor.NewFloat32([]int{4, 4}, nil, nil)
+	copy(sm.Values, []float32{
+		0, 1, 10, 10,
+		1, 0, 10, 10,
+		10, 10, 0, 2,
+		10, 10, 2, 0,
+	})
+	lbls := []string{"a", "b", "c", "d"}
+	for lk := Single; lk < LinkageN; lk++ {
+		root := ClusterTree(sm, lbls, lk)
+		ll := LeafLabels(root, lbls)
+		if len(ll) != 4 {
+			t.Errorf("%v: leaves: %v", lk, ll)
+			continue
+		}
+		// pairs must be adjacent in the leaf order
+		pos := map[string]int{}
+		for i, l := range ll {
+			pos[l] = i
+		}
+		if d := pos["a"] - pos["b"]; d != 1 && d != -1 {
+			t.Errorf("%v: a, b not adjacent: %v", lk, ll)
+		}
+		if d := pos["c"] - pos["d"]; d != 1 && d != -1 {
+			t.Errorf("%v: c, d not adjacent: %v", lk, ll)
+		}
+		mgs := Merges(root)
+		if len(mgs) != 3 {
+			t.Errorf("%v: merges: %v", lk, mgs)
+			continue
+		}
+		last := mgs[2]
+		if last.N != 4 || (last.A != 4 && last.A != 5) || (last.B != 4 && last.B != 5) {
+			t.Errorf("%v: final merge: %v", lk, last)
+		}
+		for i := 0; i < 2; i++ {
+			if mgs[i].N != 2 || mgs[i].Dist >= last.Dist {
+				t.Errorf("%v: merge %d: %v", lk, i, mgs[i])
+			}
+		}
+	}
+	// Ward: merge cost of two singletons is d^2 / 2
+	root := ClusterTree(sm, lbls, Ward)
+	mgs := Merges(root)
+	for _, mg := range mgs[:2] {
+		if mg.A < 2 && mg.Dist != 0.5 {
+			t.Errorf("ward a,b merge dist: %v", mg.Dist)
+		}
+		if mg.A >= 2 && mg.Dist != 2 {
+			t.Errorf("ward c,d merge dist: %v", mg.Dist)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package dendro provides hierarchical clustering of a similarity (distance)
+matrix into a cluster tree, with the ordered list of leaves and the
+sequence of merges and their distances (heights) needed to render a
+dendrogram, e.g., of the representational structure of layer activity
+patterns across a set of test items.
+
+The clustering itself is done by the etable clust package -- this adds
+Ward linkage and a simpler, plotting-oriented interface to the result.
+*/
+package dendro
@@ -0,0 +1,201 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package checkpoint provides saving and restoring of the full network state
+at different epochs of a long training run, with each checkpoint stored in
+its own epoch-numbered subdirectory of a checkpoint directory.
+
+The network state is everything written by Network.WriteWtsJSON, which
+includes all the weights and any additional layer-level state saved in the
+weights MetaData by the algorithm (e.g., the ActAvg running averages in leabra),
+so the weights file in each checkpoint can also be opened directly with
+Network.OpenWtsJSON.  The epoch and other metadata are stored in a gob file.
+
+Files are written atomically (to a temporary file that is then renamed),
+and the meta file is written last, marking the checkpoint as complete:
+any existing meta file for the epoch is removed before the weights are
+written, and a checkpoint directory without a meta file is ignored, so
+a crash in the middle of a Save never leaves a corrupt checkpoint.  Set Checkpointer.KeepN to automatically
+remove the oldest checkpoints, so that long runs do not fill the disk.
+*/
+package checkpoint
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/emer/emeranal"
+)
+
+const (
+	// DirPrefix is the prefix for each checkpoint subdirectory, followed by the epoch number
+	DirPrefix = "epoch_"
+
+	// WtsFile is the name of the weights file within each checkpoint subdirectory
+	WtsFile = "weights.wts"
+
+	// MetaFile is the name of the gob-encoded Meta file within each checkpoint subdirectory
+	MetaFile = "meta.gob"
+)
+
+// Meta is the metadata stored with each checkpoint
+type Meta struct {
+	Epoch   int               `desc:"epoch at which the checkpoint was saved"`
+	Network string            `desc:"name of the network"`
+	Time    time.Time         `desc:"time when the checkpoint was saved"`
+	Info    map[string]string `desc:"any additional information to save with the checkpoint, e.g., run number, current stats"`
+}
+
+// Checkpointer saves and loads network checkpoints.
+type Checkpointer struct {
+	Info  map[string]string `desc:"additional information that is saved in the Meta of each checkpoint -- set this prior to Save as needed"`
+	KeepN int               `desc:"if > 0, Save removes the oldest checkpoints in the directory so that at most this many remain"`
+	Meta  Meta              `desc:"metadata for the checkpoint that was most recently saved or loaded"`
+}
+
+// EpochDir returns the subdirectory of given checkpoint directory for given epoch
+func EpochDir(dir string, epoch int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%06d", DirPrefix, epoch))
+}
+
+// Save saves the state of the network at given epoch in an epoch-numbered
+// subdirectory of given directory, which is created if it does not exist.
+// Any existing checkpoint for the same epoch is overwritten.
+// If KeepN > 0, the oldest checkpoints beyond KeepN are then removed (see Prune).
+func (ck *Checkpointer) Save(net emer.Network, epoch int, dir string) error {
+	edir := EpochDir(dir, epoch)
+	if err := os.MkdirAll(edir, 0755); err != nil {
+		return err
+	}
+	mfn := filepath.Join(edir, MetaFile)
+	if err := os.Remove(mfn); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	err := writeAtomic(filepath.Join(edir, WtsFile), func(w io.Writer) error {
+		net.WriteWtsJSON(w)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	ck.Meta = Meta{Epoch: epoch, Network: net.Name(), Time: time.Now(), Info: ck.Info}
+	err = writeAtomic(mfn, func(w io.Writer) error {
+		return gob.NewEncoder(w).Encode(&ck.Meta)
+	})
+	if err != nil {
+		return err
+	}
+	if ck.KeepN > 0 {
+		return Prune(dir, ck.KeepN)
+	}
+	return nil
+}
+
+// writeAtomic writes to a temporary file in the same directory as fname
+// using given function, and then renames it to fname, so that fname is
+// never left partially written.
+func writeAtomic(fname string, wrfun func(w io.Writer) error) error {
+	tmp := fname + ".tmp"
+	fp, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(fp)
+	err = wrfun(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = fp.Sync()
+	}
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, fname)
+}
+
+// Load restores the state of the network from the checkpoint saved at given
+// epoch in given directory, and sets Meta to its metadata.
+// Returns an error if the checkpoint is not complete (has no meta file).
+// The topology of the saved weights is first checked against the network
+// (see emeranal.VerifyWtsTopology), and an error is returned on any mismatch.
+func (ck *Checkpointer) Load(net emer.Network, epoch int, dir string) error {
+	edir := EpochDir(dir, epoch)
+	mf, err := os.Open(filepath.Join(edir, MetaFile))
+	if err != nil {
+		return err
+	}
+	defer mf.Close()
+	var meta Meta
+	if err := gob.NewDecoder(mf).Decode(&meta); err != nil {
+		return fmt.Errorf("checkpoint.Load: epoch %d: %v", epoch, err)
+	}
+	fp, err := os.Open(filepath.Join(edir, WtsFile))
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	if err := emeranal.ReadWtsJSONVerify(net, bufio.NewReader(fp)); err != nil {
+		return err
+	}
+	ck.Meta = meta
+	return nil
+}
+
+// ListCheckpoints returns the sorted list of epochs for which complete
+// checkpoints (with a meta file) have been saved in given directory.
+func ListCheckpoints(dir string) ([]int, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var epcs []int
+	for _, fi := range fis {
+		if !fi.IsDir() || !strings.HasPrefix(fi.Name(), DirPrefix) {
+			continue
+		}
+		var epc int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(fi.Name(), DirPrefix), "%d", &epc); err != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, fi.Name(), MetaFile)); err != nil {
+			continue
+		}
+		epcs = append(epcs, epc)
+	}
+	sort.Ints(epcs)
+	return epcs, nil
+}
+
+// Prune removes the oldest checkpoints in given directory, so that at most
+// keepN (the most recent epochs) remain.
+func Prune(dir string, keepN int) error {
+	epcs, err := ListCheckpoints(dir)
+	if err != nil {
+		return err
+	}
+	if len(epcs) <= keepN {
+		return nil
+	}
+	for _, epc := range epcs[:len(epcs)-keepN] {
+		if err := os.RemoveAll(EpochDir(dir, epc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
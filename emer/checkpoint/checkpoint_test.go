@@ -0,0 +1,152 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/internal/emertest"
+	"github.com/emer/emergent/prjn"
+)
+
+// testNet returns a built network with In -> Hid full projection,
+// with weights set to distinct values
+func testNet() (*emertest.Network, *emertest.Prjn) {
+	net := emertest.NewNetwork("Test")
+	in := net.AddLayer("In", []int{2, 3}, emer.Input)
+	hid := net.AddLayer("Hid", []int{2, 2}, emer.Hidden)
+	pj := net.ConnectLayers(in, hid, prjn.NewFull(), emer.Forward).(*emertest.Prjn)
+	net.Build()
+	for i := range pj.Wt {
+		pj.Wt[i] = float32(i) / float32(len(pj.Wt))
+	}
+	return net, pj
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	net, pj := testNet()
+	orig := append([]float32{}, pj.Wt...)
+	ck := &Checkpointer{Info: map[string]string{"Run": "1"}}
+	if err := ck.Save(net, 10, dir); err != nil {
+		t.Fatal(err)
+	}
+	pj.Wt[0] = 5
+	if err := ck.Save(net, 2, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// load into a separate network with different weights
+	lnet, lpj := testNet()
+	for i := range lpj.Wt {
+		lpj.Wt[i] = -1
+	}
+	ck = &Checkpointer{}
+	if err := ck.Load(lnet, 10, dir); err != nil {
+		t.Fatal(err)
+	}
+	for i, wt := range lpj.Wt {
+		if wt != orig[i] {
+			t.Errorf("weight %d: got %g, want %g", i, wt, orig[i])
+		}
+	}
+	if ck.Meta.Epoch != 10 || ck.Meta.Network != "Test" || ck.Meta.Info["Run"] != "1" {
+		t.Errorf("meta: %+v", ck.Meta)
+	}
+	if err := ck.Load(lnet, 2, dir); err != nil || lpj.Wt[0] != 5 {
+		t.Errorf("epoch 2: wt: %g err: %v", lpj.Wt[0], err)
+	}
+
+	epcs, err := ListCheckpoints(dir)
+	if err != nil || len(epcs) != 2 || epcs[0] != 2 || epcs[1] != 10 {
+		t.Errorf("ListCheckpoints: %v %v", epcs, err)
+	}
+	if err := ck.Load(lnet, 3, dir); err == nil {
+		t.Errorf("expected error for missing epoch")
+	}
+}
+
+func TestKeepN(t *testing.T) {
+	dir := t.TempDir()
+	net, _ := testNet()
+	ck := &Checkpointer{KeepN: 2}
+	for epc := 0; epc < 5; epc++ {
+		if err := ck.Save(net, epc*10, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	epcs, err := ListCheckpoints(dir)
+	if err != nil || len(epcs) != 2 || epcs[0] != 30 || epcs[1] != 40 {
+		t.Errorf("got epochs: %v %v, want [30 40]", epcs, err)
+	}
+	if err := ck.Load(net, 40, dir); err != nil {
+		t.Error(err)
+	}
+	if err := ck.Load(net, 0, dir); err == nil {
+		t.Errorf("expected error loading pruned epoch")
+	}
+	tmps, _ := filepath.Glob(filepath.Join(dir, "*", "*.tmp"))
+	if len(tmps) != 0 {
+		t.Errorf("temporary files left: %v", tmps)
+	}
+
+	// a Save that crashed after writing the weights has no meta file,
+	// so it is not listed and cannot be loaded
+	if err := ck.Save(net, 50, dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(EpochDir(dir, 50), MetaFile)); err != nil {
+		t.Fatal(err)
+	}
+	if epcs, _ := ListCheckpoints(dir); len(epcs) != 1 || epcs[0] != 40 {
+		t.Errorf("incomplete checkpoint listed: %v", epcs)
+	}
+	if err := ck.Load(net, 50, dir); err == nil {
+		t.Errorf("expected error loading checkpoint without meta file")
+	}
+}
+
+func TestLoadTopologyMismatch(t *testing.T) {
+	dir := t.TempDir()
+	net, _ := testNet()
+	ck := &Checkpointer{}
+	if err := ck.Save(net, 1, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// same layer names, but the receiving layer is a different size
+	onet := emertest.NewNetwork("Test")
+	in := onet.AddLayer("In", []int{2, 3}, emer.Input)
+	hid := onet.AddLayer("Hid", []int{3, 2}, emer.Hidden)
+	opj := onet.ConnectLayers(in, hid, prjn.NewFull(), emer.Forward).(*emertest.Prjn)
+	onet.Build()
+	for i := range opj.Wt {
+		opj.Wt[i] = -1
+	}
+	ck.Meta = Meta{}
+	if err := ck.Load(onet, 1, dir); err == nil {
+		t.Errorf("expected topology mismatch error")
+	}
+	for i, wt := range opj.Wt {
+		if wt != -1 {
+			t.Fatalf("weight %d modified by rejected load: %g", i, wt)
+		}
+	}
+	if ck.Meta.Epoch != 0 {
+		t.Errorf("Meta changed by rejected load: %+v", ck.Meta)
+	}
+
+	// missing projection
+	onet = emertest.NewNetwork("Test")
+	onet.AddLayer("In", []int{2, 3}, emer.Input)
+	onet.AddLayer("Hid", []int{2, 2}, emer.Hidden)
+	onet.Build()
+	if err := ck.Load(onet, 1, dir); err == nil {
+		t.Errorf("expected error for missing projection")
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/emer/emergent/emer"
+)
+
+// ActAvgStats holds running (exponential moving average) statistics of a
+// unit variable (typically Act) for a single unit, across trials.
+type ActAvgStats struct {
+	Mean float32 `desc:"running average of the value"`
+	Var  float32 `desc:"running variance of the value around Mean"`
+	N    int     `desc:"number of trials accumulated"`
+	NOff int     `desc:"number of trials on which the value was at or below the ActAvgs OffThr threshold"`
+}
+
+// Update updates the running mean and variance with given new value, using
+// the given rate constant: mean = (1-dt)*mean + dt*val
+func (as *ActAvgStats) Update(val, dt float32) {
+	d := val - as.Mean
+	as.Mean += dt * d
+	as.Var = (1 - dt) * (as.Var + dt*d*d)
+}
+
+// ActAvgs maintains running per-unit ActAvgStats for all the layers in a
+// network, for monitoring long-term activity levels, e.g., for homeostasis
+// or to find units that are never active.  It works with any algorithm,
+// using the Layer UnitVals interface: call Update once per trial, at the
+// point where the values are to be sampled (e.g., at the end of the trial).
+type ActAvgs struct {
+	Var     string                   `def:"Act" desc:"unit variable to accumulate"`
+	Dt      float32                  `def:"0.01" min:"0" max:"1" desc:"rate constant for the running averages, per trial (1 / time constant)"`
+	OffThr  float32                  `def:"0.1" desc:"threshold at or below which a unit is counted as inactive on a given trial, in NOff"`
+	NTrials int                      `inactive:"+" desc:"number of trials accumulated since Reset"`
+	Stats   map[string][]ActAvgStats `view:"-" desc:"stats per unit, for each layer by name"`
+	Vals    []float32                `view:"-" desc:"buffer for current values"`
+}
+
+func (aa *ActAvgs) Defaults() {
+	aa.Var = "Act"
+	aa.Dt = 0.01
+	aa.OffThr = 0.1
+}
+
+// Reset clears all the accumulated statistics.  The first Update
+// after a Reset initializes the Mean to the current values.
+func (aa *ActAvgs) Reset() {
+	aa.Stats = nil
+	aa.NTrials = 0
+}
+
+// Update updates the running stats for all units in all layers of the
+// network that are not Off, from the current values of Var.
+// Returns error if Var is not a valid unit variable.
+func (aa *ActAvgs) Update(net emer.Network) error {
+	if aa.Stats == nil {
+		aa.Stats = make(map[string][]ActAvgStats)
+	}
+	nl := net.NLayers()
+	for li := 0; li < nl; li++ {
+		ly := net.Layer(li)
+		if ly.IsOff() {
+			continue
+		}
+		if err := ly.UnitVals(&aa.Vals, aa.Var); err != nil {
+			return err
+		}
+		vals := aa.Vals[:ly.Shape().Len()] // buffer is shared across layers
+		st, has := aa.Stats[ly.Name()]
+		if !has || len(st) != len(vals) {
+			st = make([]ActAvgStats, len(vals))
+			for i, v := range vals {
+				st[i].Mean = v
+			}
+			aa.Stats[ly.Name()] = st
+		} else {
+			for i, v := range vals {
+				st[i].Update(v, aa.Dt)
+			}
+		}
+		for i, v := range vals {
+			st[i].N++
+			if v <= aa.OffThr {
+				st[i].NOff++
+			}
+		}
+	}
+	aa.NTrials++
+	return nil
+}
+
+// LayerStats returns the stats for all units in given layer,
+// or an error if the layer has not been accumulated.
+func (aa *ActAvgs) LayerStats(lay string) ([]ActAvgStats, error) {
+	st, has := aa.Stats[lay]
+	if !has {
+		return nil, fmt.Errorf("emeranal.ActAvgs: no stats for layer named: %v", lay)
+	}
+	return st, nil
+}
+
+// UnitStats returns the stats for given unit index in given layer,
+// or an error if the layer has not been accumulated or the index is invalid.
+func (aa *ActAvgs) UnitStats(lay string, unitIdx int) (ActAvgStats, error) {
+	st, err := aa.LayerStats(lay)
+	if err != nil {
+		return ActAvgStats{}, err
+	}
+	if unitIdx < 0 || unitIdx >= len(st) {
+		return ActAvgStats{}, fmt.Errorf("emeranal.ActAvgs: unit index: %d out of range for layer: %v", unitIdx, lay)
+	}
+	return st[unitIdx], nil
+}
+
+// DeadUnit records a unit found by ActAvgs.DeadUnits
+type DeadUnit struct {
+	Layer   string  `desc:"name of the layer"`
+	UnitIdx int     `desc:"1D index of the unit within the layer"`
+	MeanAct float32 `desc:"running average value of the unit"`
+}
+
+// DeadUnits returns the units that are chronically inactive: those whose
+// running Mean is below minActThresh, and that were inactive (at or below
+// OffThr) on at least minFracTrials proportion of the trials accumulated.
+// These may indicate connectivity problems, or wasted capacity.
+// Layers are reported in name order.
+func (aa *ActAvgs) DeadUnits(minActThresh, minFracTrials float32) []DeadUnit {
+	lays := make([]string, 0, len(aa.Stats))
+	for lnm := range aa.Stats {
+		lays = append(lays, lnm)
+	}
+	sort.Strings(lays)
+	var dus []DeadUnit
+	for _, lnm := range lays {
+		for i, st := range aa.Stats[lnm] {
+			if st.N == 0 || st.Mean >= minActThresh || float32(st.NOff) < minFracTrials*float32(st.N) {
+				continue
+			}
+			dus = append(dus, DeadUnit{Layer: lnm, UnitIdx: i, MeanAct: st.Mean})
+		}
+	}
+	return dus
+}
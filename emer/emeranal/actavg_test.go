@@ -0,0 +1,123 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"testing"
+
+	"github.com/chewxy/math32"
+	"github.com/emer/emergent/emer/emeranal"
+)
+
+func TestActAvgs(t *testing.T) {
+	net, _ := testNet()
+	hid := net.Lays[1]
+	act := hid.Vals["Act"]
+	aa := &emeranal.ActAvgs{}
+	aa.Defaults()
+	aa.Dt = 0.5
+	// unit 0: 1, 0, 1, 0; unit 1 constant .4
+	for trl, v := range []float32{1, 0, 1, 0} {
+		act[0] = v
+		act[1] = 0.4
+		if err := aa.Update(net); err != nil {
+			t.Fatal(err)
+		}
+		if aa.NTrials != trl+1 {
+			t.Errorf("NTrials: %d != %d", aa.NTrials, trl+1)
+		}
+	}
+	st, err := aa.UnitStats("Hid", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// first Update sets Mean = 1, then Update with each later value
+	mean, vr := float32(1), float32(0)
+	for _, v := range []float32{0, 1, 0} {
+		d := v - mean
+		mean += 0.5 * d
+		vr = 0.5 * (vr + 0.5*d*d)
+	}
+	if mean != 0.375 || math32.Abs(st.Mean-mean) > 1e-6 || math32.Abs(st.Var-vr) > 1e-6 {
+		t.Errorf("unit 0 stats: %+v, expected mean: %g var: %g", st, mean, vr)
+	}
+	if st.N != 4 || st.NOff != 2 {
+		t.Errorf("unit 0 N: %d NOff: %d, expected 4, 2", st.N, st.NOff)
+	}
+	st, _ = aa.UnitStats("Hid", 1)
+	if st.Mean != 0.4 || st.Var != 0 || st.NOff != 0 {
+		t.Errorf("constant unit 1 stats: %+v", st)
+	}
+	hs, _ := aa.LayerStats("Hid")
+	is, _ := aa.LayerStats("In")
+	if len(hs) != 4 || len(is) != 6 {
+		t.Errorf("layer stats sizes: Hid: %d In: %d", len(hs), len(is))
+	}
+	if _, err := aa.UnitStats("Hid", 4); err == nil {
+		t.Errorf("expected error for unit index out of range")
+	}
+	if _, err := aa.LayerStats("Nope"); err == nil {
+		t.Errorf("expected error for unknown layer")
+	}
+	aa.Reset()
+	if aa.NTrials != 0 || aa.Stats != nil {
+		t.Errorf("Reset did not clear stats")
+	}
+}
+
+func TestDeadUnits(t *testing.T) {
+	net, _ := testNet()
+	in := net.Lays[0].Vals["Act"]
+	act := net.Lays[1].Vals["Act"]
+	aa := &emeranal.ActAvgs{}
+	aa.Defaults()
+	aa.Dt = 1 // Mean = last value
+	if dus := aa.DeadUnits(0.05, 0.9); dus != nil {
+		t.Errorf("dead units before Update: %v", dus)
+	}
+	for i := range in {
+		in[i] = 0.5
+	}
+	for trl := 0; trl < 10; trl++ {
+		act[0] = 0    // never active
+		act[1] = 0    // active only on the first trial
+		act[2] = 0.05 // at OffThr, but Mean not below .05
+		act[3] = 0.5  // always active
+		if trl == 0 {
+			act[1] = 0.9
+		}
+		aa.Update(net)
+	}
+	cases := []struct {
+		minAct, minFrac float32
+		units           []int
+	}{
+		{0.05, 0.9, []int{0, 1}}, // unit 1 inactive on exactly .9 of trials
+		{0.05, 0.95, []int{0}},
+		{0.06, 1, []int{0, 2}},
+		{0, 0, nil},
+	}
+	for _, c := range cases {
+		dus := aa.DeadUnits(c.minAct, c.minFrac)
+		var units []int
+		for _, du := range dus {
+			if du.Layer != "Hid" {
+				t.Errorf("minAct: %g minFrac: %g unexpected dead unit: %+v", c.minAct, c.minFrac, du)
+				continue
+			}
+			units = append(units, du.UnitIdx)
+		}
+		if len(units) != len(c.units) {
+			t.Errorf("minAct: %g minFrac: %g dead units: %v != %v", c.minAct, c.minFrac, units, c.units)
+			continue
+		}
+		for i := range units {
+			if units[i] != c.units[i] {
+				t.Errorf("minAct: %g minFrac: %g dead units: %v != %v", c.minAct, c.minFrac, units, c.units)
+				break
+			}
+		}
+	}
+}
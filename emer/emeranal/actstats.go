@@ -0,0 +1,79 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"github.com/chewxy/math32"
+	"github.com/emer/emergent/emer"
+)
+
+// ActStats holds summary statistics of a unit variable (typically Act)
+// over a set of units, e.g., in a layer or a pool within a layer.
+type ActStats struct {
+	Mean   float32 `desc:"mean value across units"`
+	Max    float32 `desc:"maximum value across units"`
+	Min    float32 `desc:"minimum value across units"`
+	PctAct float32 `desc:"proportion of units (0-1) with values above threshold"`
+}
+
+// Compute computes the stats over given values, using thr as the
+// threshold for counting a unit as active in PctAct.
+func (as *ActStats) Compute(vals []float32, thr float32) {
+	*as = ActStats{}
+	n := len(vals)
+	if n == 0 {
+		return
+	}
+	as.Max = -math32.MaxFloat32
+	as.Min = math32.MaxFloat32
+	nact := 0
+	for _, v := range vals {
+		as.Mean += v
+		as.Max = math32.Max(as.Max, v)
+		as.Min = math32.Min(as.Min, v)
+		if v > thr {
+			nact++
+		}
+	}
+	as.Mean /= float32(n)
+	as.PctAct = float32(nact) / float32(n)
+}
+
+// LayerActStats returns the ActStats for given unit variable (typically Act)
+// over all the units in the layer, with thr as the threshold for PctAct.
+// Returns error on invalid var name.
+func LayerActStats(ly emer.Layer, varNm string, thr float32) (ActStats, error) {
+	var as ActStats
+	var vals []float32
+	if err := ly.UnitVals(&vals, varNm); err != nil {
+		return as, err
+	}
+	as.Compute(vals[:ly.Shape().Len()], thr)
+	return as, nil
+}
+
+// LayerPoolActStats returns the ActStats for given unit variable (typically Act)
+// for each pool in the layer (outer 2 dimensions of a 4D layer, in row-major order),
+// with thr as the threshold for PctAct.  For a 2D layer, the entire layer is
+// treated as one pool.  Returns error on invalid var name.
+func LayerPoolActStats(ly emer.Layer, varNm string, thr float32) ([]ActStats, error) {
+	var vals []float32
+	if err := ly.UnitVals(&vals, varNm); err != nil {
+		return nil, err
+	}
+	shp := ly.Shape()
+	npool := 1
+	nu := shp.Len()
+	if ly.Is4D() {
+		npool = shp.Dim(0) * shp.Dim(1)
+		nu = shp.Dim(2) * shp.Dim(3)
+	}
+	pss := make([]ActStats, npool)
+	for pi := range pss {
+		st := pi * nu
+		pss[pi].Compute(vals[st:st+nu], thr)
+	}
+	return pss, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"testing"
+
+	"github.com/chewxy/math32"
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/emer/emergent/internal/emertest"
+)
+
+func actStatsEq(a, b emeranal.ActStats) bool {
+	return math32.Abs(a.Mean-b.Mean) < 1e-6 && a.Max == b.Max && a.Min == b.Min && math32.Abs(a.PctAct-b.PctAct) < 1e-6
+}
+
+func TestLayerActStats(t *testing.T) {
+	net := emertest.NewNetwork("Test")
+	in := net.AddLayer("In", []int{2, 3}, emer.Input)
+	hid := net.AddLayer("Hid", []int{2, 1, 1, 2}, emer.Hidden)
+	net.Build()
+	copy(in.Vals["Act"], []float32{0, .2, .9, 1, -.3, .5})
+	copy(hid.Vals["Act"], []float32{.1, .8, .6, .6})
+
+	as, err := emeranal.LayerActStats(in, "Act", .5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := emeranal.ActStats{Mean: 2.3 / 6, Max: 1, Min: -.3, PctAct: 2.0 / 6}
+	if !actStatsEq(as, want) {
+		t.Errorf("In stats: %+v != %+v", as, want)
+	}
+	as, _ = emeranal.LayerActStats(hid, "Act", .5)
+	want = emeranal.ActStats{Mean: .525, Max: .8, Min: .1, PctAct: .75}
+	if !actStatsEq(as, want) {
+		t.Errorf("Hid stats: %+v != %+v", as, want)
+	}
+
+	pss, err := emeranal.LayerPoolActStats(hid, "Act", .5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pwant := []emeranal.ActStats{{Mean: .45, Max: .8, Min: .1, PctAct: .5}, {Mean: .6, Max: .6, Min: .6, PctAct: 1}}
+	if len(pss) != 2 || !actStatsEq(pss[0], pwant[0]) || !actStatsEq(pss[1], pwant[1]) {
+		t.Errorf("Hid pool stats: %+v != %+v", pss, pwant)
+	}
+	pss, _ = emeranal.LayerPoolActStats(in, "Act", .5)
+	if len(pss) != 1 || pss[0].Max != 1 {
+		t.Errorf("2D layer should be one pool: %+v", pss)
+	}
+
+	var es emeranal.ActStats
+	es.Compute(nil, .5)
+	if es != (emeranal.ActStats{}) {
+		t.Errorf("empty stats: %+v", es)
+	}
+	if _, err := emeranal.LayerActStats(in, "NoSuchVar", .5); err == nil {
+		t.Errorf("expected error for invalid var")
+	}
+	if _, err := emeranal.LayerPoolActStats(in, "NoSuchVar", .5); err == nil {
+		t.Errorf("expected error for invalid var")
+	}
+}
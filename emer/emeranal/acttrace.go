@@ -0,0 +1,113 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"fmt"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etensor"
+)
+
+// ActTrace records the values of a unit variable (typically Act) for
+// layers in a network at every cycle of a trial, for analyzing the
+// dynamics of settling.  Call Reset at the start of each trial and
+// Record after each cycle, and then Trace to get the results.
+// When On is false, Record returns immediately, so the calls can be
+// left in the cycle loop at minimal cost.
+type ActTrace struct {
+	On      bool                 `desc:"whether to record -- Record does nothing if false"`
+	Var     string               `def:"Act" desc:"unit variable to record"`
+	Lays    []string             `desc:"names of layers to record -- all layers if empty"`
+	NCycles int                  `inactive:"+" desc:"number of cycles recorded since Reset"`
+	Vals    map[string][]float32 `view:"-" desc:"recorded values for each layer: cycle x unit"`
+	Cur     []float32            `view:"-" desc:"buffer for current values"`
+}
+
+// Reset clears the recorded values, e.g., at the start of each trial.
+// The memory is retained for re-use in the next trial.
+func (at *ActTrace) Reset() {
+	at.NCycles = 0
+	for lnm, vals := range at.Vals {
+		at.Vals[lnm] = vals[:0]
+	}
+}
+
+// Record appends the current values of Var for the layers to the trace,
+// if On.  Returns error if a layer is not found or Var is not valid,
+// in which case nothing is recorded for this cycle.
+func (at *ActTrace) Record(net emer.Network) error {
+	if !at.On {
+		return nil
+	}
+	if at.Var == "" {
+		at.Var = "Act"
+	}
+	if at.Vals == nil {
+		at.Vals = make(map[string][]float32)
+	}
+	lays, err := at.layers(net)
+	if err != nil {
+		return err
+	}
+	prv := make([]int, len(lays))
+	for i, ly := range lays {
+		prv[i] = len(at.Vals[ly.Name()])
+		if err := at.recordLayer(ly); err != nil {
+			for j, ply := range lays[:i] { // undo partial cycle
+				at.Vals[ply.Name()] = at.Vals[ply.Name()][:prv[j]]
+			}
+			return err
+		}
+	}
+	at.NCycles++
+	return nil
+}
+
+// layers returns the layers to record: those named in Lays,
+// or all layers if empty.  Returns error if a layer is not found.
+func (at *ActTrace) layers(net emer.Network) ([]emer.Layer, error) {
+	if len(at.Lays) == 0 {
+		nl := net.NLayers()
+		lays := make([]emer.Layer, nl)
+		for li := range lays {
+			lays[li] = net.Layer(li)
+		}
+		return lays, nil
+	}
+	lays := make([]emer.Layer, len(at.Lays))
+	for i, lnm := range at.Lays {
+		ly, err := net.LayerByNameTry(lnm)
+		if err != nil {
+			return nil, err
+		}
+		lays[i] = ly
+	}
+	return lays, nil
+}
+
+// recordLayer appends the current values for given layer
+func (at *ActTrace) recordLayer(ly emer.Layer) error {
+	if err := ly.UnitVals(&at.Cur, at.Var); err != nil {
+		return err
+	}
+	cur := at.Cur[:ly.Shape().Len()] // buffer is shared across layers
+	at.Vals[ly.Name()] = append(at.Vals[ly.Name()], cur...)
+	return nil
+}
+
+// Trace returns a copy of the recorded values for given layer,
+// as a tensor of shape [cycle, unit].  Returns an error if the
+// layer has not been recorded.
+func (at *ActTrace) Trace(lay string) (*etensor.Float32, error) {
+	vals, has := at.Vals[lay]
+	if !has || len(vals) == 0 || at.NCycles == 0 {
+		return nil, fmt.Errorf("emeranal.ActTrace: no values recorded for layer named: %v", lay)
+	}
+	nu := len(vals) / at.NCycles
+	tsr := etensor.NewFloat32([]int{at.NCycles, nu}, nil, []string{"Cycle", "Unit"})
+	copy(tsr.Values, vals)
+	return tsr, nil
+}
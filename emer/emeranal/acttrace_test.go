@@ -0,0 +1,74 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/emer/emergent/emer/emeranal"
+)
+
+func TestActTrace(t *testing.T) {
+	net, _ := testNet()
+	act := net.Lays[1].Vals["Act"]
+	at := &emeranal.ActTrace{}
+	at.Record(net)
+	if at.NCycles != 0 {
+		t.Errorf("recorded when not On")
+	}
+	at.On = true
+	for cyc := 0; cyc < 3; cyc++ {
+		for i := range act {
+			act[i] = float32(cyc*10 + i)
+		}
+		if err := at.Record(net); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hid, err := at.Trace("Hid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hid.Shapes(), []int{3, 4}) || hid.Value([]int{2, 3}) != 23 || hid.Value([]int{1, 0}) != 10 {
+		t.Errorf("Hid trace: %v", hid)
+	}
+	if in, _ := at.Trace("In"); in == nil || !reflect.DeepEqual(in.Shapes(), []int{3, 6}) {
+		t.Errorf("In trace: %v", in)
+	}
+	at.Reset()
+	at.Lays = []string{"Hid"}
+	at.Record(net)
+	if _, err := at.Trace("In"); err == nil {
+		t.Errorf("expected error for layer not recorded since Reset")
+	}
+
+	// failed Records must not leave partial cycles in the trace
+	at.Reset()
+	at.Lays = nil
+	at.Record(net)
+	at.Lays = []string{"Hid", "Nope"}
+	if err := at.Record(net); err == nil {
+		t.Errorf("expected error for unknown layer")
+	}
+	at.Lays = nil
+	at.Var = "ActM" // In records ActM, but then Hid fails
+	delete(net.Lays[1].Vals, "ActM")
+	if err := at.Record(net); err == nil {
+		t.Errorf("expected error for invalid var")
+	}
+	at.Var = "Act"
+	at.Record(net)
+	if at.NCycles != 2 {
+		t.Errorf("NCycles: %d != 2", at.NCycles)
+	}
+	hid, err = at.Trace("Hid")
+	if err != nil || !reflect.DeepEqual(hid.Shapes(), []int{2, 4}) || hid.Value([]int{1, 3}) != 23 {
+		t.Errorf("Hid trace after failed Records: %v %v", hid, err)
+	}
+	if in, err := at.Trace("In"); err != nil || !reflect.DeepEqual(in.Shapes(), []int{2, 6}) {
+		t.Errorf("In trace after failed Records: %v %v", in, err)
+	}
+}
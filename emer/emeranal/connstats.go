@@ -0,0 +1,95 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// PrjnConnStats holds structural statistics of the connectivity of a projection
+type PrjnConnStats struct {
+	NConn      int     `desc:"total number of connections (synapses)"`
+	MeanFanIn  float32 `desc:"mean number of connections per receiving unit"`
+	MeanFanOut float32 `desc:"mean number of connections per sending unit"`
+	MinFanIn   float32 `desc:"minimum number of connections for any receiving unit"`
+	MaxFanIn   float32 `desc:"maximum number of connections for any receiving unit"`
+	MinFanOut  float32 `desc:"minimum number of connections for any sending unit"`
+	MaxFanOut  float32 `desc:"maximum number of connections for any sending unit"`
+	Density    float32 `desc:"proportion of all possible sending x receiving connections that exist: NConn / (NSend * NRecv)"`
+}
+
+// ConnStats returns the structural connectivity statistics for given
+// projection, determined by checking for the existence of the Wt synapse
+// variable between all sending and receiving units (see SynsFunc).
+// This works for any projection, but visits every possible pair of units,
+// so it is intended for periodic analysis.
+func ConnStats(pj emer.Prjn) PrjnConnStats {
+	var cs PrjnConnStats
+	ns := pj.SendLay().Shape().Len()
+	nr := pj.RecvLay().Shape().Len()
+	if ns == 0 || nr == 0 {
+		return cs
+	}
+	fanIn := make([]int, nr)
+	fanOut := make([]int, ns)
+	if err := SynsFunc(pj, "Wt", func(si, ri int, wt float32) {
+		fanIn[ri]++
+		fanOut[si]++
+	}); err != nil {
+		return cs
+	}
+	cs.MinFanIn, cs.MaxFanIn = minMaxInts(fanIn)
+	cs.MinFanOut, cs.MaxFanOut = minMaxInts(fanOut)
+	for _, fi := range fanIn {
+		cs.NConn += fi
+	}
+	cs.MeanFanIn = float32(cs.NConn) / float32(nr)
+	cs.MeanFanOut = float32(cs.NConn) / float32(ns)
+	cs.Density = float32(cs.NConn) / float32(ns*nr)
+	return cs
+}
+
+// minMaxInts returns the min and max of given non-empty list of counts
+func minMaxInts(cnts []int) (min, max float32) {
+	mn, mx := cnts[0], cnts[0]
+	for _, c := range cnts {
+		if c < mn {
+			mn = c
+		}
+		if c > mx {
+			mx = c
+		}
+	}
+	return float32(mn), float32(mx)
+}
+
+// ConnStatsTable returns a table with the ConnStats for each projection in
+// the network, one per row, for reports.
+func ConnStatsTable(net emer.Network) *etable.Table {
+	pjs := NetPrjns(net)
+	sc := etable.Schema{
+		{Name: "Send", Type: etensor.STRING},
+		{Name: "Recv", Type: etensor.STRING},
+		{Name: "Type", Type: etensor.STRING},
+		{Name: "NConn", Type: etensor.INT64},
+	}
+	for _, cn := range []string{"MeanFanIn", "MinFanIn", "MaxFanIn", "MeanFanOut", "MinFanOut", "MaxFanOut", "Density"} {
+		sc = append(sc, etable.Column{Name: cn, Type: etensor.FLOAT32})
+	}
+	dt := etable.New(sc, len(pjs))
+	dt.SetMetaData("name", net.Name()+"_ConnStats")
+	for i, pj := range pjs {
+		cs := ConnStats(pj)
+		dt.SetCellStringIdx(0, i, pj.SendLay().Name())
+		dt.SetCellStringIdx(1, i, pj.RecvLay().Name())
+		dt.SetCellStringIdx(2, i, pj.PrjnTypeName())
+		for ci, v := range []float32{float32(cs.NConn), cs.MeanFanIn, cs.MinFanIn, cs.MaxFanIn, cs.MeanFanOut, cs.MinFanOut, cs.MaxFanOut, cs.Density} {
+			dt.SetCellFloatIdx(3+ci, i, float64(v))
+		}
+	}
+	return dt
+}
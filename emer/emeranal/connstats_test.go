@@ -0,0 +1,64 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/emer/emergent/internal/emertest"
+	"github.com/emer/emergent/prjn"
+)
+
+// connNet returns testNet with an added 2x2 Out layer receiving a OneToOne
+// projection from In units 1..4, so In units 0 and 5 have no connections,
+// along with the Full and OneToOne projections.
+func connNet() (*emertest.Network, emer.Prjn, emer.Prjn) {
+	net, full := testNet()
+	out := net.AddLayer("Out", []int{2, 2}, emer.Target)
+	oto := prjn.NewOneToOne()
+	oto.SendStart = 1
+	one := net.ConnectLayers(net.Lays[0], out, oto, emer.Forward)
+	net.Build()
+	return net, full, one
+}
+
+func TestConnStats(t *testing.T) {
+	_, full, one := connNet()
+
+	cases := []struct {
+		pj   emer.Prjn
+		want emeranal.PrjnConnStats
+	}{
+		{full, emeranal.PrjnConnStats{NConn: 24, MeanFanIn: 6, MeanFanOut: 4, MinFanIn: 6, MaxFanIn: 6, MinFanOut: 4, MaxFanOut: 4, Density: 1}},
+		{one, emeranal.PrjnConnStats{NConn: 4, MeanFanIn: 1, MeanFanOut: float32(4) / 6, MinFanIn: 1, MaxFanIn: 1, MinFanOut: 0, MaxFanOut: 1, Density: float32(4) / 24}},
+	}
+	for _, c := range cases {
+		if cs := emeranal.ConnStats(c.pj); cs != c.want {
+			t.Errorf("%v: ConnStats: %+v != %+v", c.pj.Name(), cs, c.want)
+		}
+	}
+}
+
+func TestConnStatsTable(t *testing.T) {
+	net, _, one := connNet()
+	dt := emeranal.ConnStatsTable(net)
+	if dt.Rows != 2 {
+		t.Fatalf("rows: %d != 2", dt.Rows)
+	}
+	if dt.CellString("Send", 1) != "In" || dt.CellString("Recv", 1) != "Out" || dt.CellString("Type", 1) != "Forward" {
+		t.Errorf("row 1: %v -> %v %v", dt.CellString("Send", 1), dt.CellString("Recv", 1), dt.CellString("Type", 1))
+	}
+	cs := emeranal.ConnStats(one)
+	for cn, v := range map[string]float32{"NConn": float32(cs.NConn), "MeanFanOut": cs.MeanFanOut, "MinFanOut": 0, "MaxFanOut": 1, "Density": cs.Density} {
+		if tv := dt.CellFloat(cn, 1); tv != float64(v) {
+			t.Errorf("row 1 %v: %g != %g", cn, tv, v)
+		}
+	}
+	if dt.CellFloat("NConn", 0) != 24 || dt.CellFloat("MinFanOut", 0) != 4 {
+		t.Errorf("row 0 NConn: %g MinFanOut: %g", dt.CellFloat("NConn", 0), dt.CellFloat("MinFanOut", 0))
+	}
+}
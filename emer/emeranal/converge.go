@@ -0,0 +1,73 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import "github.com/emer/emergent/emer"
+
+// Converge detects when the activations of a network have stabilized within
+// a trial (settled), so that the cycle loop can be stopped early.
+// Call Reset at the start of each trial, and then Converged after each cycle,
+// which compares the current unit values against those at the previous call.
+type Converge struct {
+	Var     string      `def:"Act" desc:"unit variable to check for convergence"`
+	MaxDiff float32     `inactive:"+" desc:"maximum absolute difference in Var across all units, from the most recent call to Converged"`
+	Prev    [][]float32 `view:"-" desc:"values of Var for each layer at the previous call to Converged"`
+	Cur     []float32   `view:"-" desc:"buffer for current values"`
+	Valid   bool        `view:"-" desc:"true if Prev has values from a previous call since Reset"`
+}
+
+// Reset must be called at the start of each trial, so that the first
+// call to Converged just records the initial values.
+func (cv *Converge) Reset() {
+	cv.Valid = false
+	cv.MaxDiff = 0
+}
+
+// Converged returns true if the maximum absolute change in the Var
+// (Act by default) values across all units in all layers (that are not Off)
+// since the previous call is below tol.  The first call after a Reset always
+// returns false.  Returns false if Var is not a valid unit variable.
+func (cv *Converge) Converged(net emer.Network, tol float32) bool {
+	if cv.Var == "" {
+		cv.Var = "Act"
+	}
+	nl := net.NLayers()
+	if len(cv.Prev) != nl {
+		cv.Prev = make([][]float32, nl)
+		cv.Valid = false
+	}
+	cv.MaxDiff = 0
+	for li := 0; li < nl; li++ {
+		ly := net.Layer(li)
+		if ly.IsOff() {
+			continue
+		}
+		if err := ly.UnitVals(&cv.Cur, cv.Var); err != nil {
+			cv.Valid = false
+			return false
+		}
+		prv := cv.Prev[li]
+		if len(prv) != len(cv.Cur) {
+			prv = make([]float32, len(cv.Cur))
+			cv.Prev[li] = prv
+			cv.Valid = false
+		}
+		for i, v := range cv.Cur {
+			d := v - prv[i]
+			if d < 0 {
+				d = -d
+			}
+			if d > cv.MaxDiff {
+				cv.MaxDiff = d
+			}
+			prv[i] = v
+		}
+	}
+	if !cv.Valid {
+		cv.Valid = true
+		return false
+	}
+	return cv.MaxDiff < tol
+}
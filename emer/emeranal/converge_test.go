@@ -0,0 +1,76 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer/emeranal"
+)
+
+func TestConverge(t *testing.T) {
+	net, _ := testNet()
+	act := net.Lays[1].Vals["Act"]
+	cv := &emeranal.Converge{}
+	cv.Reset()
+	// settles geometrically toward 1: diffs .5, .25, .125, .0625
+	const tol = 0.1
+	conv := -1
+	for cyc := 0; cyc < 5; cyc++ {
+		act[2] = 1 - 1/float32(int(1)<<uint(cyc))
+		if cv.Converged(net, tol) {
+			conv = cyc
+			break
+		}
+		if cyc > 0 && cv.MaxDiff != 1/float32(int(1)<<uint(cyc)) {
+			t.Errorf("cycle %d MaxDiff: %g", cyc, cv.MaxDiff)
+		}
+	}
+	if conv != 4 {
+		t.Errorf("converged at cycle: %d, expected 4", conv)
+	}
+
+	// first call after Reset is always false, even with no change
+	cv.Reset()
+	if cv.Converged(net, tol) {
+		t.Errorf("first call after Reset should not converge")
+	}
+	if !cv.Converged(net, tol) || cv.MaxDiff != 0 {
+		t.Errorf("unchanged values should converge, MaxDiff: %g", cv.MaxDiff)
+	}
+
+	// oscillating values never converge, so the caller's max cycles stops it
+	cv.Reset()
+	for cyc := 0; cyc < 20; cyc++ {
+		act[0] = float32(cyc % 2)
+		if cv.Converged(net, tol) {
+			t.Fatalf("oscillating values converged at cycle: %d", cyc)
+		}
+	}
+	if cv.MaxDiff != 1 {
+		t.Errorf("oscillating MaxDiff: %g", cv.MaxDiff)
+	}
+
+	// Off layers are ignored
+	cv.Reset()
+	cv.Converged(net, tol)
+	net.Lays[1].Off = true
+	act[0] = 5
+	if !cv.Converged(net, tol) {
+		t.Errorf("change in Off layer should be ignored, MaxDiff: %g", cv.MaxDiff)
+	}
+	net.Lays[1].Off = false
+
+	cv.Var = "NoSuchVar"
+	cv.Reset()
+	for cyc := 0; cyc < 3; cyc++ {
+		if cv.Converged(net, tol) {
+			t.Errorf("invalid Var should never converge")
+		}
+	}
+	if cv.Valid {
+		t.Errorf("invalid Var should leave Valid false")
+	}
+}
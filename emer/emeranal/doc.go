@@ -0,0 +1,12 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package emeranal provides generic analysis and manipulation functions for
+networks, written purely in terms of the emer interfaces (emer.Network,
+emer.Layer, emer.Prjn), so that they work for any algorithm that implements
+them: e.g., SynsFunc for visiting all the synapses in a Prjn, PrjnWtStats,
+PrjnRF, ConnStats, ActAvgs, and WtsSnapshot for saving and restoring weights.
+*/
+package emeranal
@@ -0,0 +1,60 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/emer"
+)
+
+// DOTColors are the edge colors used for projections in ExportDOT,
+// indexed by the PrjnType value (modulo the number of colors), so that
+// each type of projection, including those defined by specialized
+// algorithms (e.g., BurstCtxt, BurstTRC in deep), has a distinct color.
+var DOTColors = []string{"black", "blue", "darkgreen", "red", "purple", "orange", "brown", "magenta", "cyan4", "gold3"}
+
+// ExportDOT writes the layer and projection topology of the network to
+// given writer in the Graphviz DOT format, e.g., for rendering with the
+// dot command.  Each layer is a node labeled with its name, type and shape,
+// and each projection is a directed edge from the sending to the receiving
+// layer, labeled with its type and number of synapses (weights), and colored
+// according to its type (see DOTColors).  Layers that are Off are drawn dashed.
+func ExportDOT(net emer.Network, w io.Writer) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "digraph %s {\n", strconv.Quote(net.Name()))
+	b.WriteString("\tnode [shape=box];\n")
+	nl := net.NLayers()
+	for li := 0; li < nl; li++ {
+		ly := net.Layer(li)
+		shp := strings.Trim(fmt.Sprint(ly.Shape().Shp), "[]")
+		lbl := fmt.Sprintf("%s\n%s [%s]", ly.Name(), ly.Type(), strings.Replace(shp, " ", ", ", -1))
+		style := ""
+		if ly.IsOff() {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(b, "\t%s [label=%s%s];\n", strconv.Quote(ly.Name()), strconv.Quote(lbl), style)
+	}
+	var vals []float32
+	for _, pj := range NetPrjns(net) {
+		nsyn := 0
+		if err := pj.SynVals(&vals, "Wt"); err == nil {
+			nsyn = len(vals)
+		}
+		lbl := fmt.Sprintf("%s\n%d wts", pj.PrjnTypeName(), nsyn)
+		clr := DOTColors[int(pj.Type())%len(DOTColors)]
+		style := ""
+		if pj.IsOff() {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(b, "\t%s -> %s [label=%s, color=%s, fontcolor=%s%s];\n", strconv.Quote(pj.SendLay().Name()), strconv.Quote(pj.RecvLay().Name()), strconv.Quote(lbl), clr, clr, style)
+	}
+	b.WriteString("}\n")
+	return b.Flush()
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/emer/emergent/internal/emertest"
+	"github.com/emer/emergent/prjn"
+)
+
+// dotGraph is the result of parsing the subset of the DOT language
+// written by ExportDOT
+type dotGraph struct {
+	name  string
+	nodes map[string]map[string]string // attrs by node id
+	edges [][2]string                  // send, recv
+	eattr []map[string]string          // attrs for each edge
+}
+
+// dotTokens splits DOT source into tokens: quoted strings (unquoted,
+// with \" and \\ escapes resolved), identifiers, and punctuation,
+// including ->
+func dotTokens(src string) ([]string, error) {
+	var toks []string
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case strings.IndexByte("{}[];=,", c) >= 0:
+			toks = append(toks, string(c))
+			i++
+		case strings.HasPrefix(src[i:], "->"):
+			toks = append(toks, "->")
+			i += 2
+		case c == '"':
+			var sb strings.Builder
+			sb.WriteByte('"')
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				sb.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			toks = append(toks, sb.String())
+			i = j + 1
+		default:
+			j := i
+			for j < len(src) && strings.IndexByte(" \t\n{}[];=,\"", src[j]) < 0 && !strings.HasPrefix(src[j:], "->") {
+				j++
+			}
+			toks = append(toks, src[i:j])
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+// parseDOT parses: digraph ID { stmt* } where stmt is
+// (node attrs | ID attrs? | ID -> ID attrs?) ;
+func parseDOT(src string) (*dotGraph, error) {
+	toks, err := dotTokens(src)
+	if err != nil {
+		return nil, err
+	}
+	p := 0
+	next := func() string {
+		if p >= len(toks) {
+			return ""
+		}
+		p++
+		return toks[p-1]
+	}
+	id := func() (string, error) {
+		t := next()
+		if t == "" || strings.IndexByte("{}[];=,", t[0]) >= 0 || t == "->" {
+			return "", fmt.Errorf("expected ID, got: %q", t)
+		}
+		return strings.TrimPrefix(t, "\""), nil
+	}
+	attrs := func() (map[string]string, error) {
+		am := map[string]string{}
+		if p < len(toks) && toks[p] == "[" {
+			next()
+			for {
+				k, err := id()
+				if err != nil {
+					return nil, err
+				}
+				if t := next(); t != "=" {
+					return nil, fmt.Errorf("expected =, got: %q", t)
+				}
+				v, err := id()
+				if err != nil {
+					return nil, err
+				}
+				am[k] = v
+				t := next()
+				if t == "]" {
+					break
+				}
+				if t != "," {
+					return nil, fmt.Errorf("expected , or ], got: %q", t)
+				}
+			}
+		}
+		return am, nil
+	}
+	g := &dotGraph{nodes: map[string]map[string]string{}}
+	if t := next(); t != "digraph" {
+		return nil, fmt.Errorf("expected digraph, got: %q", t)
+	}
+	if g.name, err = id(); err != nil {
+		return nil, err
+	}
+	if t := next(); t != "{" {
+		return nil, fmt.Errorf("expected {, got: %q", t)
+	}
+	for p < len(toks) && toks[p] != "}" {
+		if toks[p] == "node" {
+			next()
+			if _, err := attrs(); err != nil {
+				return nil, err
+			}
+		} else {
+			a, err := id()
+			if err != nil {
+				return nil, err
+			}
+			if p < len(toks) && toks[p] == "->" {
+				next()
+				b, err := id()
+				if err != nil {
+					return nil, err
+				}
+				am, err := attrs()
+				if err != nil {
+					return nil, err
+				}
+				g.edges = append(g.edges, [2]string{a, b})
+				g.eattr = append(g.eattr, am)
+			} else {
+				am, err := attrs()
+				if err != nil {
+					return nil, err
+				}
+				g.nodes[a] = am
+			}
+		}
+		if t := next(); t != ";" {
+			return nil, fmt.Errorf("expected ;, got: %q", t)
+		}
+	}
+	if t := next(); t != "}" {
+		return nil, fmt.Errorf("expected }, got: %q", t)
+	}
+	if p != len(toks) {
+		return nil, fmt.Errorf("extra tokens after graph: %v", toks[p:])
+	}
+	return g, nil
+}
+
+func TestExportDOT(t *testing.T) {
+	net := emertest.NewNetwork(`My "Net"`)
+	in := net.AddLayer("In", []int{2, 3}, emer.Input)
+	hid := net.AddLayer(`Hid {1}; a\b`, []int{2, 2}, emer.Hidden)
+	out := net.AddLayer("Out -> x", []int{2, 2, 1, 3}, emer.Target)
+	net.ConnectLayers(in, hid, prjn.NewFull(), emer.Forward)
+	net.ConnectLayers(hid, out, prjn.NewOneToOne(), emer.Forward)
+	net.ConnectLayers(out, hid, prjn.NewFull(), emer.Back)
+	net.Build()
+	out.Off = true
+
+	var b bytes.Buffer
+	if err := emeranal.ExportDOT(net, &b); err != nil {
+		t.Fatal(err)
+	}
+	src := b.String()
+	g, err := parseDOT(src)
+	if err != nil {
+		t.Fatalf("DOT output does not parse: %v\n%s", err, src)
+	}
+	if g.name != `My "Net"` {
+		t.Errorf("graph name: %q", g.name)
+	}
+	if len(g.nodes) != 3 {
+		t.Errorf("nodes: %v", g.nodes)
+	}
+	for _, ly := range net.Lays {
+		am, has := g.nodes[ly.Nm]
+		if !has {
+			t.Errorf("no node for layer: %q", ly.Nm)
+			continue
+		}
+		if !strings.HasPrefix(am["label"], ly.Nm+`n`+ly.Typ.String()) { // \n is unescaped to n
+			t.Errorf("layer %q label: %q", ly.Nm, am["label"])
+		}
+		if (am["style"] == "dashed") != ly.Off {
+			t.Errorf("layer %q style: %q off: %v", ly.Nm, am["style"], ly.Off)
+		}
+	}
+	if !strings.HasSuffix(g.nodes["Out -> x"]["label"], "[2, 2, 1, 3]") {
+		t.Errorf("Out label shape: %q", g.nodes["Out -> x"]["label"])
+	}
+
+	want := [][2]string{{"In", hid.Nm}, {out.Nm, hid.Nm}, {hid.Nm, out.Nm}} // by recv layer
+	nsyn := []int{24, 48, 4}
+	if len(g.edges) != len(want) {
+		t.Fatalf("edges: %v", g.edges)
+	}
+	for i, e := range g.edges {
+		if e != want[i] {
+			t.Errorf("edge %d: %q != %q", i, e, want[i])
+		}
+		if lbl := g.eattr[i]["label"]; !strings.HasSuffix(lbl, "n"+strconv.Itoa(nsyn[i])+" wts") {
+			t.Errorf("edge %d label: %q, want %d wts", i, lbl, nsyn[i])
+		}
+	}
+	if g.eattr[0]["color"] != g.eattr[2]["color"] || g.eattr[0]["color"] == g.eattr[1]["color"] {
+		t.Errorf("Forward and Back edges should have different colors: %v", g.eattr)
+	}
+	if g.eattr[0]["style"] != "" || g.eattr[1]["style"] != "dashed" || g.eattr[2]["style"] != "dashed" {
+		t.Errorf("projections to or from an Off layer should be dashed: %v", g.eattr)
+	}
+}
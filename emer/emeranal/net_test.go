@@ -0,0 +1,26 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/internal/emertest"
+	"github.com/emer/emergent/prjn"
+)
+
+// testNet returns a built network with a 2x3 In layer fully connected to
+// a 2x2 Hid layer, with the weights set to i / 24 in synapse order
+// (recv-major), and the projection.
+func testNet() (*emertest.Network, *emertest.Prjn) {
+	net := emertest.NewNetwork("Test")
+	in := net.AddLayer("In", []int{2, 3}, emer.Input)
+	hid := net.AddLayer("Hid", []int{2, 2}, emer.Hidden)
+	pj := net.ConnectLayers(in, hid, prjn.NewFull(), emer.Forward).(*emertest.Prjn)
+	net.Build()
+	for i := range pj.Wt {
+		pj.Wt[i] = float32(i) / float32(len(pj.Wt))
+	}
+	return net, pj
+}
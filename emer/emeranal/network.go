@@ -0,0 +1,84 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import "github.com/emer/emergent/emer"
+
+// NetPrjns returns all of the projections in the network, as the
+// concatenation of the receiving projections of each layer, in layer order.
+func NetPrjns(net emer.Network) emer.Prjns {
+	var pjs emer.Prjns
+	nl := net.NLayers()
+	for li := 0; li < nl; li++ {
+		pjs = append(pjs, *net.Layer(li).RecvPrjns()...)
+	}
+	return pjs
+}
+
+// EqualWts returns true if the two networks have the same layers (by name and
+// size), the same projections (by sending layer and number of synapses),
+// and exactly equal synaptic weight (Wt) values in all projections.
+// This is useful for verifying that weights have been copied correctly
+// from one network to another (e.g., via WtsSnapshot), and conversely
+// that modifying the weights in one does not affect the other.
+func EqualWts(a, b emer.Network) bool {
+	nl := a.NLayers()
+	if b.NLayers() != nl {
+		return false
+	}
+	var av, bv []float32
+	for li := 0; li < nl; li++ {
+		al := a.Layer(li)
+		bl := b.Layer(li)
+		if al.Name() != bl.Name() || al.Shape().Len() != bl.Shape().Len() {
+			return false
+		}
+		np := al.NRecvPrjns()
+		if bl.NRecvPrjns() != np {
+			return false
+		}
+		for pi := 0; pi < np; pi++ {
+			ap := al.RecvPrjn(pi)
+			bp := bl.RecvPrjn(pi)
+			if ap.SendLay().Name() != bp.SendLay().Name() {
+				return false
+			}
+			aerr := ap.SynVals(&av, "Wt")
+			berr := bp.SynVals(&bv, "Wt")
+			if (aerr == nil) != (berr == nil) || len(av) != len(bv) {
+				return false
+			}
+			for i := range av {
+				if av[i] != bv[i] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// LesionLayer turns Off (lesions) the layer of given name, using SetOff,
+// so that it does not participate in any computation.  Prjn.SetOff likewise
+// lesions individual projections.  Returns error if layer not found.
+func LesionLayer(net emer.Network, name string) error {
+	ly, err := net.LayerByNameTry(name)
+	if err != nil {
+		return err
+	}
+	ly.SetOff(true)
+	return nil
+}
+
+// UnLesionLayer turns the layer of given name back on after LesionLayer.
+// Returns error if layer not found.
+func UnLesionLayer(net emer.Network, name string) error {
+	ly, err := net.LayerByNameTry(name)
+	if err != nil {
+		return err
+	}
+	ly.SetOff(false)
+	return nil
+}
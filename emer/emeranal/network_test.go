@@ -0,0 +1,60 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer/emeranal"
+)
+
+func TestEqualWts(t *testing.T) {
+	a, ap := testNet()
+	b, bp := testNet()
+	if !emeranal.EqualWts(a, b) {
+		t.Errorf("identical networks not equal")
+	}
+	ws := &emeranal.WtsSnapshot{}
+	ws.Save(a)
+	bp.Wt[3] += .1
+	if emeranal.EqualWts(a, b) {
+		t.Errorf("changed weight not detected")
+	}
+	if ap.Wt[3] != float32(3)/24 {
+		t.Errorf("original weight changed: %v", ap.Wt[3])
+	}
+	if err := ws.Restore(b); err != nil {
+		t.Fatal(err)
+	}
+	if !emeranal.EqualWts(a, b) {
+		t.Errorf("not equal after restoring snapshot")
+	}
+	b.Lays[1].Nm = "Out"
+	if emeranal.EqualWts(a, b) {
+		t.Errorf("different layer names not detected")
+	}
+}
+
+func TestLesionLayer(t *testing.T) {
+	net, _ := testNet()
+	if err := emeranal.LesionLayer(net, "Hid"); err != nil {
+		t.Fatal(err)
+	}
+	if !net.LayerByName("Hid").IsOff() || net.LayerByName("In").IsOff() {
+		t.Errorf("only Hid should be off")
+	}
+	if err := emeranal.UnLesionLayer(net, "Hid"); err != nil {
+		t.Fatal(err)
+	}
+	if net.LayerByName("Hid").IsOff() {
+		t.Errorf("Hid still off after UnLesionLayer")
+	}
+	if err := emeranal.LesionLayer(net, "Nope"); err == nil {
+		t.Errorf("expected error for unknown layer")
+	}
+	if err := emeranal.UnLesionLayer(net, "Nope"); err == nil {
+		t.Errorf("expected error for unknown layer")
+	}
+}
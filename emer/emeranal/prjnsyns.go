@@ -0,0 +1,111 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"fmt"
+
+	"github.com/chewxy/math32"
+	"github.com/emer/emergent/emer"
+)
+
+// These are generic analysis and manipulation functions that operate on the
+// synapses of any Prjn, using only the SynValTry / SetSynVal interface methods.
+// They check every possible sending x receiving unit pair, so they are
+// intended for periodic analysis, not for use within the inner loops of a simulation.
+
+// SynsFunc calls given function for each synapse that exists in the projection,
+// passing the sending and receiving unit 1D indexes and the value of given
+// synapse variable.  Synapses are visited in receiver-based order.
+// Returns error if the variable name is not valid for this projection.
+func SynsFunc(pj emer.Prjn, varNm string, fun func(si, ri int, val float32)) error {
+	if err := SynVarCheck(pj, varNm); err != nil {
+		return err
+	}
+	ns := pj.SendLay().Shape().Len()
+	nr := pj.RecvLay().Shape().Len()
+	for ri := 0; ri < nr; ri++ {
+		for si := 0; si < ns; si++ {
+			val, err := pj.SynValTry(varNm, si, ri)
+			if err != nil { // not connected
+				continue
+			}
+			fun(si, ri, val)
+		}
+	}
+	return nil
+}
+
+// SynVarCheck returns an error if given variable name is not
+// among the SynVarNames for given projection.
+func SynVarCheck(pj emer.Prjn, varNm string) error {
+	for _, vn := range pj.SynVarNames() {
+		if vn == varNm {
+			return nil
+		}
+	}
+	return fmt.Errorf("emeranal.SynVarCheck: prjn: %v synapse variable named: %v not found", pj.Name(), varNm)
+}
+
+// PruneWts sets the weight (Wt) of all synapses in the projection whose absolute
+// value is below given threshold to 0, and returns the number of such synapses.
+// This is a masking form of pruning: the synapses still exist and are still
+// subject to learning, so it is typically done after learning is complete,
+// e.g., to measure the effects of sparsifying a trained network.
+// Returns an error if there is no Wt variable.
+func PruneWts(pj emer.Prjn, thr float32) (int, error) {
+	npr := 0
+	err := SynsFunc(pj, "Wt", func(si, ri int, wt float32) {
+		if math32.Abs(wt) >= thr {
+			return
+		}
+		npr++
+		if wt != 0 {
+			pj.SetSynVal("Wt", si, ri, 0)
+		}
+	})
+	return npr, err
+}
+
+// InitWtsFn sets the weight (Wt) of every synapse in the projection to the
+// value returned by given function of the sending and receiving unit 1D
+// indexes, e.g., for topographic initialization as a function of distance,
+// or to load weights from an external model.  This must be called after
+// the network is Built and its weights initialized (InitWts), which would
+// otherwise overwrite these weights.  Algorithms that maintain other
+// variables derived from Wt (e.g., the linear weight LWt in leabra) must
+// update those separately.  Returns an error if there is no Wt variable.
+func InitWtsFn(pj emer.Prjn, fn func(si, ri int) float32) error {
+	return SynsFunc(pj, "Wt", func(si, ri int, wt float32) {
+		pj.SetSynVal("Wt", si, ri, fn(si, ri))
+	})
+}
+
+// SymmetrizeWts sets the weights (Wt) of each pair of reciprocal synapses
+// in a recurrent projection (where the sending and receiving layers are
+// the same) to their average, so that Wt[i,j] == Wt[j,i], e.g., for the
+// analysis of energy functions.  Synapses without a reciprocal connection
+// are left unchanged.  Returns the number of pairs symmetrized, or an
+// error if the projection is not recurrent.
+func SymmetrizeWts(pj emer.Prjn) (int, error) {
+	if pj.SendLay() != pj.RecvLay() {
+		return 0, fmt.Errorf("emeranal.SymmetrizeWts: prjn: %v is not recurrent (sending and receiving layers differ)", pj.Name())
+	}
+	npr := 0
+	err := SynsFunc(pj, "Wt", func(si, ri int, wt float32) {
+		if si >= ri { // each pair once, from the lower sending index
+			return
+		}
+		rwt, err := pj.SynValTry("Wt", ri, si)
+		if err != nil { // no reciprocal
+			return
+		}
+		avg := 0.5 * (wt + rwt)
+		pj.SetSynVal("Wt", si, ri, avg)
+		pj.SetSynVal("Wt", ri, si, avg)
+		npr++
+	})
+	return npr, err
+}
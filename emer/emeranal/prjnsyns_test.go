@@ -0,0 +1,121 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/emer/emergent/internal/emertest"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestInitWtsFn(t *testing.T) {
+	_, full, one := connNet()
+	fn := func(si, ri int) float32 { return float32(si*10 + ri) }
+	if err := emeranal.InitWtsFn(full, fn); err != nil {
+		t.Fatal(err)
+	}
+	pj := full.(*emertest.Prjn)
+	for ri := 0; ri < 4; ri++ {
+		for si := 0; si < 6; si++ {
+			if wt := pj.Wt[ri*6+si]; wt != fn(si, ri) {
+				t.Errorf("si: %d ri: %d Wt: %g != %g", si, ri, wt, fn(si, ri))
+			}
+		}
+	}
+
+	// only existing synapses are visited: In 1..4 -> Out 0..3
+	var calls [][2]int
+	err := emeranal.InitWtsFn(one, func(si, ri int) float32 {
+		calls = append(calls, [2]int{si, ri})
+		return 0.5
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 4 {
+		t.Fatalf("fn called: %d times != 4: %v", len(calls), calls)
+	}
+	opj := one.(*emertest.Prjn)
+	for _, c := range calls {
+		si, ri := c[0], c[1]
+		if si != ri+1 {
+			t.Errorf("fn called with si: %d ri: %d, not a OneToOne synapse", si, ri)
+		}
+		if wt := opj.Wt[ri*6+si]; wt != 0.5 {
+			t.Errorf("si: %d ri: %d Wt: %g != 0.5", si, ri, wt)
+		}
+	}
+
+	pj.Vars = []string{"DWt"}
+	if err := emeranal.InitWtsFn(pj, fn); err == nil {
+		t.Errorf("expected error for missing Wt variable")
+	}
+}
+
+func TestSymmetrizeWts(t *testing.T) {
+	net, _, _ := connNet()
+	hid := net.Lays[1]
+	full := prjn.NewFull()
+	full.SelfCon = true
+	pj := net.ConnectLayers(hid, hid, full, emer.Lateral).(*emertest.Prjn)
+	pj.Build()
+	wt := func(si, ri int) float32 { return float32(si*10 + ri) }
+	emeranal.InitWtsFn(pj, wt)
+	n, err := emeranal.SymmetrizeWts(pj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 6 {
+		t.Errorf("symmetrized pairs: %d != 6", n)
+	}
+	for ri := 0; ri < 4; ri++ {
+		for si := 0; si < 4; si++ {
+			want := 0.5 * (wt(si, ri) + wt(ri, si))
+			if w := pj.Wt[ri*4+si]; w != want {
+				t.Errorf("si: %d ri: %d Wt: %g != %g", si, ri, w, want)
+			}
+		}
+	}
+
+	// OneToOne self projection has no reciprocal pairs
+	one := net.ConnectLayers(hid, hid, prjn.NewOneToOne(), emer.Lateral).(*emertest.Prjn)
+	one.Build()
+	one.Wt[1*4+1] = 0.3
+	if n, err := emeranal.SymmetrizeWts(one); err != nil || n != 0 || one.Wt[1*4+1] != 0.3 {
+		t.Errorf("OneToOne: pairs: %d err: %v wt: %g", n, err, one.Wt[1*4+1])
+	}
+
+	if _, err := emeranal.SymmetrizeWts(net.Lays[1].Rcv[0]); err == nil {
+		t.Errorf("expected error for non-recurrent projection")
+	}
+}
+
+func TestPruneWts(t *testing.T) {
+	_, pj := testNet() // weights 0, 1/24 .. 23/24
+	pj.Wt[1] = -pj.Wt[1]
+	n, err := emeranal.PruneWts(pj, 0.1) // 0, +-1/24, 2/24
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("pruned: %d != 3", n)
+	}
+	for i, wt := range pj.Wt {
+		want := float32(i) / 24
+		if i < 3 {
+			want = 0
+		}
+		if wt != want {
+			t.Errorf("Wt[%d]: %g != %g", i, wt, want)
+		}
+	}
+	pj.Vars = []string{"DWt"}
+	if _, err := emeranal.PruneWts(pj, 0.1); err == nil {
+		t.Errorf("expected error for missing Wt variable")
+	}
+}
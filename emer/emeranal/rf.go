@@ -0,0 +1,99 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"fmt"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etensor"
+)
+
+// PrjnRF returns the receptive fields of the receiving units in the
+// projection, as the values of given synapse variable (typically Wt) in a
+// tensor of shape [NRecv, sending layer shape...], so that entry
+// [ri, sy, sx] is the value for the synapse from sending unit (sy, sx) to
+// receiving unit ri (1D index).  Values are 0 where units are not connected.
+// Returns error if the variable is not valid.
+func PrjnRF(pj emer.Prjn, varNm string) (*etensor.Float32, error) {
+	sshp := pj.SendLay().Shape()
+	ns := sshp.Len()
+	nr := pj.RecvLay().Shape().Len()
+	shp := append([]int{nr}, sshp.Shp...)
+	var nms []string
+	if len(sshp.Nms) == len(sshp.Shp) {
+		nms = append([]string{"Recv"}, sshp.Nms...)
+	}
+	rf := etensor.NewFloat32(shp, nil, nms)
+	err := SynsFunc(pj, varNm, func(si, ri int, val float32) {
+		rf.Values[ri*ns+si] = val
+	})
+	return rf, err
+}
+
+// RecvWtsToTensor fills given tensor with the weights (Wt) of all the
+// synapses into receiving unit ri (1D index) in the projection, in the
+// shape of the sending layer (which is set on the tensor if it does not
+// already match), so that the value at [sy, sx] is the weight from the
+// sending unit at that position.  Values are 0 for sending units that are
+// not connected.  See PrjnRF for all the receiving units at once.
+// Returns error if the projection does not have a Wt synapse variable,
+// or ri is out of range.
+func RecvWtsToTensor(pj emer.Prjn, ri int, tsr *etensor.Float32) error {
+	if err := SynVarCheck(pj, "Wt"); err != nil {
+		return err
+	}
+	nr := pj.RecvLay().Shape().Len()
+	if ri < 0 || ri >= nr {
+		return fmt.Errorf("emeranal.RecvWtsToTensor: prjn: %v receiving unit index: %d out of range: %d", pj.Name(), ri, nr)
+	}
+	sshp := pj.SendLay().Shape()
+	if !etensor.EqualInts(tsr.Shapes(), sshp.Shp) {
+		tsr.SetShape(sshp.Shp, nil, sshp.Nms)
+	}
+	for si := range tsr.Values {
+		wt, err := pj.SynValTry("Wt", si, ri)
+		if err != nil { // not connected
+			wt = 0
+		}
+		tsr.Values[si] = wt
+	}
+	return nil
+}
+
+// LayerRF returns the weight receptive fields (see PrjnRF) of the units in
+// given layer, for the projection it receives from the named sending layer.
+// Returns error if there is no such projection.
+func LayerRF(ly emer.Layer, sendName string) (*etensor.Float32, error) {
+	pj, err := ly.RecvPrjns().SendNameTry(sendName)
+	if err != nil {
+		return nil, err
+	}
+	return PrjnRF(pj, "Wt")
+}
+
+// AvgRF returns the average over all the receiving units of the receptive
+// fields returned by PrjnRF or LayerRF, in the shape of the sending layer,
+// which shows the overall pattern of weights from the sending layer.
+func AvgRF(rf *etensor.Float32) *etensor.Float32 {
+	nr, ns := rf.RowCellSize()
+	var nms []string
+	if rf.DimNames() != nil {
+		nms = rf.DimNames()[1:]
+	}
+	avg := etensor.NewFloat32(append([]int{}, rf.Shapes()[1:]...), nil, nms)
+	if nr == 0 {
+		return avg
+	}
+	for ri := 0; ri < nr; ri++ {
+		for si, v := range rf.Values[ri*ns : (ri+1)*ns] {
+			avg.Values[si] += v
+		}
+	}
+	for si := range avg.Values {
+		avg.Values[si] /= float32(nr)
+	}
+	return avg
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/emer/etable/etensor"
+)
+
+func TestPrjnRF(t *testing.T) {
+	net, pj := testNet()
+	rf, err := emeranal.PrjnRF(pj, "Wt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rf.Shapes(), []int{4, 2, 3}) {
+		t.Fatalf("shape: %v, want [4 2 3]", rf.Shapes())
+	}
+	for ri := 0; ri < 4; ri++ {
+		for si := 0; si < 6; si++ { // wt = (ri * 6 + si) / 24
+			if got, want := rf.Value([]int{ri, si / 3, si % 3}), float32(ri*6+si)/24; got != want {
+				t.Errorf("rf[%d, %d]: got %g, want %g", ri, si, got, want)
+			}
+		}
+	}
+	if _, err := emeranal.PrjnRF(pj, "Nope"); err == nil {
+		t.Errorf("expected error for invalid variable")
+	}
+
+	// LayerRF: only hid unit 1 receives from send unit (1, 1)
+	for i := range pj.Wt {
+		pj.Wt[i] = 0
+	}
+	pj.SetSynVal("Wt", 4, 1, 1)
+	hid := net.LayerByName("Hid")
+	rf, err = emeranal.LayerRF(hid, "In")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ri := 0; ri < 4; ri++ {
+		for si := 0; si < 6; si++ {
+			want := float32(0)
+			if ri == 1 && si == 4 {
+				want = 1
+			}
+			if got := rf.Value([]int{ri, si / 3, si % 3}); got != want {
+				t.Errorf("layer rf[%d, %d]: got %g, want %g", ri, si, got, want)
+			}
+		}
+	}
+	if _, err := emeranal.LayerRF(hid, "Nope"); err == nil {
+		t.Errorf("expected error for unknown sending layer")
+	}
+
+	avg := emeranal.AvgRF(rf)
+	if !reflect.DeepEqual(avg.Shapes(), []int{2, 3}) {
+		t.Errorf("avg shape: %v, want [2 3]", avg.Shapes())
+	}
+	for si, v := range avg.Values {
+		want := float32(0)
+		if si == 4 {
+			want = .25
+		}
+		if v != want {
+			t.Errorf("avg[%d]: got %g, want %g", si, v, want)
+		}
+	}
+	// AvgRF must not alias the shape of the rf
+	avg.Shapes()[0] = 9
+	if rf.Dim(1) != 2 {
+		t.Errorf("AvgRF shares shape with rf")
+	}
+}
+
+func TestRecvWtsToTensor(t *testing.T) {
+	_, pj := testNet()
+	tsr := &etensor.Float32{}
+	if err := emeranal.RecvWtsToTensor(pj, 2, tsr); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tsr.Shapes(), []int{2, 3}) {
+		t.Errorf("shape: %v, want sending layer shape [2 3]", tsr.Shapes())
+	}
+	for si := 0; si < 6; si++ { // wt = (ri * 6 + si) / 24
+		if want := float32(2*6+si) / 24; tsr.Values[si] != want {
+			t.Errorf("sender %d: got %g, want %g", si, tsr.Values[si], want)
+		}
+	}
+	if err := emeranal.RecvWtsToTensor(pj, 4, tsr); err == nil {
+		t.Errorf("expected error for receiving index out of range")
+	}
+
+	// a projection without a Wt variable must not silently fill the tensor
+	pj.Vars = []string{"DWt"}
+	tsr.Values[0] = -1
+	if err := emeranal.RecvWtsToTensor(pj, 2, tsr); err == nil {
+		t.Errorf("expected error for missing Wt variable")
+	}
+	if tsr.Values[0] != -1 {
+		t.Errorf("tensor modified on error")
+	}
+}
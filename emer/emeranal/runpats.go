@@ -0,0 +1,53 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"fmt"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// RunPatterns calls given trial function for each row of the given pattern
+// table, and records the values of the given unit variable (e.g., "Act")
+// for each of the named output layers after each trial, returning a
+// results table with one row per pattern and one column per output layer
+// (named by the layer, with the layer's shape as the cell shape).
+// The trial function is responsible for applying the inputs from the given
+// row and running the network through a full trial (e.g., all quarters / cycles)
+// according to the algorithm, with or without learning as desired --
+// this generic interface does not know how to do these algorithm-specific steps.
+// Any error returned by the trial function stops the run and is returned.
+func RunPatterns(net emer.Network, dt *etable.Table, outLays []string, varNm string, trial func(row int) error) (*etable.Table, error) {
+	lays := make([]emer.Layer, len(outLays))
+	sc := etable.Schema{}
+	for i, lnm := range outLays {
+		ly, err := net.LayerByNameTry(lnm)
+		if err != nil {
+			return nil, err
+		}
+		lays[i] = ly
+		sc = append(sc, etable.Column{Name: lnm, Type: etensor.FLOAT32, CellShape: ly.Shape().Shp, DimNames: ly.Shape().Nms})
+	}
+	rt := etable.New(sc, dt.Rows)
+	rt.SetMetaData("name", net.Name()+"_"+varNm)
+	var vals []float32
+	for row := 0; row < dt.Rows; row++ {
+		if err := trial(row); err != nil {
+			return rt, err
+		}
+		for i, ly := range lays {
+			if err := ly.UnitVals(&vals, varNm); err != nil {
+				return rt, fmt.Errorf("emeranal.RunPatterns: layer %v: %v", ly.Name(), err)
+			}
+			ct := rt.Cols[i].(*etensor.Float32)
+			n := ly.Shape().Len()
+			copy(ct.Values[row*n:(row+1)*n], vals)
+		}
+	}
+	return rt, nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestRunPatterns(t *testing.T) {
+	net, _ := testNet()
+	in, hid := net.Lays[0], net.Lays[1]
+	dt := etable.New(etable.Schema{
+		{Name: "Input", Type: etensor.FLOAT32, CellShape: []int{2, 3}},
+	}, 3)
+	ic := dt.Cols[0].(*etensor.Float32)
+	for i := range ic.Values {
+		ic.Values[i] = float32(i)
+	}
+	// trial applies the input row and sets each Hid unit to the sum of
+	// the inputs plus the unit index
+	var rows []int
+	trial := func(row int) error {
+		rows = append(rows, row)
+		copy(in.Vals["Act"], ic.Values[row*6:(row+1)*6])
+		sum := float32(0)
+		for _, v := range in.Vals["Act"] {
+			sum += v
+		}
+		for i := range hid.Vals["Act"] {
+			hid.Vals["Act"][i] = sum + float32(i)
+		}
+		return nil
+	}
+	rt, err := emeranal.RunPatterns(net, dt, []string{"In", "Hid"}, "Act", trial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0] != 0 || rows[2] != 2 {
+		t.Errorf("trial rows: %v", rows)
+	}
+	if rt.Rows != 3 || rt.ColNames[0] != "In" || rt.ColNames[1] != "Hid" {
+		t.Fatalf("results table: %d rows, cols: %v", rt.Rows, rt.ColNames)
+	}
+	if hs := rt.Cols[1].Shapes(); len(hs) != 3 || hs[1] != 2 || hs[2] != 2 {
+		t.Errorf("Hid cell shape: %v", hs)
+	}
+	for row := 0; row < 3; row++ {
+		sum := float32(0)
+		for i := 0; i < 6; i++ {
+			v := float32(row*6 + i)
+			sum += v
+			if got := rt.CellTensorIdx(0, row).(*etensor.Float32).Values[i]; got != v {
+				t.Errorf("row %d In unit %d: %g != %g", row, i, got, v)
+			}
+		}
+		for i := 0; i < 4; i++ {
+			if got := rt.CellTensorIdx(1, row).(*etensor.Float32).Values[i]; got != sum+float32(i) {
+				t.Errorf("row %d Hid unit %d: %g != %g", row, i, got, sum+float32(i))
+			}
+		}
+	}
+
+	stop := errors.New("stop")
+	rows = nil
+	_, err = emeranal.RunPatterns(net, dt, []string{"Hid"}, "Act", func(row int) error {
+		rows = append(rows, row)
+		if row == 1 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || len(rows) != 2 {
+		t.Errorf("trial error should stop the run: %v, rows: %v", err, rows)
+	}
+	if _, err := emeranal.RunPatterns(net, dt, []string{"NoSuchLayer"}, "Act", trial); err == nil {
+		t.Errorf("expected error for missing layer")
+	}
+	if _, err := emeranal.RunPatterns(net, dt, []string{"Hid"}, "NoSuchVar", trial); err == nil {
+		t.Errorf("expected error for invalid var")
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import "github.com/emer/emergent/emer"
+
+// LayerSSE returns the sum-squared-error over the units in the layer,
+// between the actVar unit variable (e.g., ActM, the minus-phase activation)
+// and the targVar target variable (e.g., Targ or Ext for the clamped target,
+// or ActP for the plus-phase activation).  Differences with an absolute
+// value below tol count as 0, e.g., tol = 0.5 for binary targets, so that
+// the SSE reflects the number of units on the wrong side of 0.5.
+// Returns error if either variable is not valid.
+func LayerSSE(ly emer.Layer, actVar, targVar string, tol float32) (float32, error) {
+	var acts, targs []float32
+	if err := ly.UnitVals(&acts, actVar); err != nil {
+		return 0, err
+	}
+	if err := ly.UnitVals(&targs, targVar); err != nil {
+		return 0, err
+	}
+	n := ly.Shape().Len()
+	sse := float32(0)
+	for i, tg := range targs[:n] {
+		d := acts[i] - tg
+		if d < tol && d > -tol {
+			continue
+		}
+		sse += d * d
+	}
+	return sse, nil
+}
+
+// NetSSE returns the total LayerSSE over the named layers (e.g., the output
+// layers) in the network, using the same args as LayerSSE.
+// Returns error if a layer is not found or a variable is not valid.
+func NetSSE(net emer.Network, lays []string, actVar, targVar string, tol float32) (float32, error) {
+	sse := float32(0)
+	for _, lnm := range lays {
+		ly, err := net.LayerByNameTry(lnm)
+		if err != nil {
+			return 0, err
+		}
+		lsse, err := LayerSSE(ly, actVar, targVar, tol)
+		if err != nil {
+			return 0, err
+		}
+		sse += lsse
+	}
+	return sse, nil
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"testing"
+
+	"github.com/chewxy/math32"
+	"github.com/emer/emergent/emer/emeranal"
+)
+
+func TestSSE(t *testing.T) {
+	net, _ := testNet()
+	in, hid := net.Lays[0], net.Lays[1]
+	copy(hid.Vals["ActM"], []float32{0.9, 0.3, 0.6, 0})
+	copy(hid.Vals["Targ"], []float32{1, 0, 0, 1})
+	// diffs: -.1, .3, .6, -1
+	sse, err := emeranal.LayerSSE(hid, "ActM", "Targ", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := float32(.01 + .09 + .36 + 1); math32.Abs(sse-want) > 1e-6 {
+		t.Errorf("SSE tol 0: %g != %g", sse, want)
+	}
+	sse, _ = emeranal.LayerSSE(hid, "ActM", "Targ", 0.5)
+	if want := float32(.36 + 1); math32.Abs(sse-want) > 1e-6 {
+		t.Errorf("SSE tol .5: %g != %g", sse, want)
+	}
+	sse, _ = emeranal.LayerSSE(hid, "ActM", "Targ", 1.5)
+	if sse != 0 {
+		t.Errorf("SSE tol 1.5: %g != 0", sse)
+	}
+
+	in.Vals["ActM"][5] = 0.5
+	nsse, err := emeranal.NetSSE(net, []string{"Hid", "In"}, "ActM", "Targ", 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := float32(.36 + 1 + .25); math32.Abs(nsse-want) > 1e-6 {
+		t.Errorf("NetSSE: %g != %g", nsse, want)
+	}
+
+	if _, err := emeranal.LayerSSE(hid, "ActM", "NoSuchVar", 0); err == nil {
+		t.Errorf("expected error for invalid targ var")
+	}
+	if _, err := emeranal.NetSSE(net, []string{"Hid", "NoSuchLayer"}, "ActM", "Targ", 0); err == nil {
+		t.Errorf("expected error for missing layer")
+	}
+}
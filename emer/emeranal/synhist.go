@@ -0,0 +1,98 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"fmt"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// SynIdx identifies one synapse by sending and receiving unit 1D indexes
+type SynIdx struct {
+	Si int `desc:"sending unit index"`
+	Ri int `desc:"receiving unit index"`
+}
+
+// SynapseHistory records the trajectory of the weights (or other
+// synapse variable) of selected synapses in a projection over time,
+// for debugging learning dynamics.  Register the synapses of interest
+// with Watch, and call Record at each point in time to be recorded
+// (e.g., the end of each trial).
+type SynapseHistory struct {
+	Prjn   emer.Prjn   `desc:"the projection to record from"`
+	Var    string      `def:"Wt" desc:"synapse variable to record"`
+	Syns   []SynIdx    `desc:"the synapses being recorded"`
+	Hist   [][]float32 `view:"-" desc:"recorded values: time x synapse (in order of Syns)"`
+	Epochs []int       `view:"-" desc:"epoch for each recorded time step"`
+	Trials []int       `view:"-" desc:"trial for each recorded time step"`
+}
+
+// NewSynapseHistory returns a new SynapseHistory recording
+// the Wt variable from given projection.
+func NewSynapseHistory(pj emer.Prjn) *SynapseHistory {
+	return &SynapseHistory{Prjn: pj, Var: "Wt"}
+}
+
+// Watch adds given synapse, by sending and receiving unit 1D indexes,
+// to those being recorded.  Returns an error if the synapse does not exist.
+// Must be called before recording starts, as the history is Reset.
+func (sh *SynapseHistory) Watch(si, ri int) error {
+	if _, err := sh.Prjn.SynValTry(sh.Var, si, ri); err != nil {
+		return fmt.Errorf("emeranal.SynapseHistory: prjn: %v synapse si: %d ri: %d: %v", sh.Prjn.Name(), si, ri, err)
+	}
+	sh.Syns = append(sh.Syns, SynIdx{Si: si, Ri: ri})
+	sh.Reset()
+	return nil
+}
+
+// Reset clears the recorded history, keeping the watched synapses
+func (sh *SynapseHistory) Reset() {
+	sh.Hist = nil
+	sh.Epochs = nil
+	sh.Trials = nil
+}
+
+// Record appends the current values of all watched synapses to the history,
+// labeled with given epoch and trial.  Does nothing if no synapses are watched.
+func (sh *SynapseHistory) Record(epoch, trial int) {
+	if len(sh.Syns) == 0 {
+		return
+	}
+	vals := make([]float32, len(sh.Syns))
+	for i, sy := range sh.Syns {
+		vals[i] = sh.Prjn.SynVal(sh.Var, sy.Si, sy.Ri)
+	}
+	sh.Hist = append(sh.Hist, vals)
+	sh.Epochs = append(sh.Epochs, epoch)
+	sh.Trials = append(sh.Trials, trial)
+}
+
+// Table returns the history as an etable.Table, with Epoch and Trial
+// columns followed by a column for each synapse, named S<si>_R<ri>.
+func (sh *SynapseHistory) Table() *etable.Table {
+	sc := etable.Schema{{Name: "Epoch", Type: etensor.INT64}, {Name: "Trial", Type: etensor.INT64}}
+	for _, sy := range sh.Syns {
+		sc = append(sc, etable.Column{Name: fmt.Sprintf("S%d_R%d", sy.Si, sy.Ri), Type: etensor.FLOAT32})
+	}
+	dt := etable.New(sc, len(sh.Hist))
+	dt.SetMetaData("name", sh.Prjn.Name()+"_"+sh.Var)
+	for t, vals := range sh.Hist {
+		dt.SetCellFloatIdx(0, t, float64(sh.Epochs[t]))
+		dt.SetCellFloatIdx(1, t, float64(sh.Trials[t]))
+		for i, v := range vals {
+			dt.SetCellFloatIdx(2+i, t, float64(v))
+		}
+	}
+	return dt
+}
+
+// Export saves the Table version of the history to given file, in CSV format
+func (sh *SynapseHistory) Export(fname gi.FileName) error {
+	return sh.Table().SaveCSV(fname, etable.Comma, etable.Headers)
+}
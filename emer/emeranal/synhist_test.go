@@ -0,0 +1,70 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/goki/gi/gi"
+)
+
+func TestSynapseHistory(t *testing.T) {
+	_, pj := testNet()
+	sh := emeranal.NewSynapseHistory(pj)
+	sh.Record(0, 0)
+	if len(sh.Hist) != 0 {
+		t.Errorf("recorded with no synapses watched")
+	}
+	if err := sh.Watch(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.Watch(5, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.Watch(6, 0); err == nil {
+		t.Errorf("expected error for sending index out of range")
+	}
+	seq := []float32{0.1, 0.2, 0.3}
+	for trl, wt := range seq {
+		pj.SetSynVal("Wt", 1, 2, wt)
+		sh.Record(2, trl)
+	}
+	if len(sh.Hist) != len(seq) {
+		t.Fatalf("history length: %d != %d", len(sh.Hist), len(seq))
+	}
+	fixed := float32(5) / 24 // Wt of si 5, ri 0 in testNet
+	for trl, wt := range seq {
+		if sh.Hist[trl][0] != wt || sh.Hist[trl][1] != fixed {
+			t.Errorf("trial: %d values: %v != [%g %g]", trl, sh.Hist[trl], wt, fixed)
+		}
+		if sh.Epochs[trl] != 2 || sh.Trials[trl] != trl {
+			t.Errorf("trial: %d labeled epoch: %d trial: %d", trl, sh.Epochs[trl], sh.Trials[trl])
+		}
+	}
+	dt := sh.Table()
+	if dt.Rows != 3 || dt.CellFloat("S1_R2", 2) != float64(float32(0.3)) || dt.CellFloat("Trial", 1) != 1 {
+		t.Errorf("table: rows: %d S1_R2[2]: %g", dt.Rows, dt.CellFloat("S1_R2", 2))
+	}
+	fn := filepath.Join(t.TempDir(), "synhist.csv")
+	if err := sh.Export(gi.FileName(fn)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[0], "S1_R2") || !strings.Contains(lines[0], "S5_R0") {
+		t.Errorf("exported csv:\n%s", b)
+	}
+	sh.Watch(0, 0)
+	if len(sh.Hist) != 0 || len(sh.Syns) != 3 {
+		t.Errorf("Watch should reset the history and keep the synapses")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/weights"
+)
+
+// VerifyTopology compares the topology of the two networks: layer names and
+// shapes, the sending layers of each receiving projection, and the number of
+// synapses (connections) in each projection, returning an error listing
+// every mismatch, or nil if they have the same topology.
+func VerifyTopology(a, b emer.Network) error {
+	var errs []string
+	nl := a.NLayers()
+	if b.NLayers() != nl {
+		errs = append(errs, fmt.Sprintf("number of layers: %d vs. %d", nl, b.NLayers()))
+	}
+	var av, bv []float32
+	for li := 0; li < nl; li++ {
+		al := a.Layer(li)
+		bl, err := b.LayerByNameTry(al.Name())
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("layer: %v not found in: %v", al.Name(), b.Name()))
+			continue
+		}
+		if !al.Shape().IsEqual(bl.Shape()) {
+			errs = append(errs, fmt.Sprintf("layer: %v shape: %v vs. %v", al.Name(), al.Shape().Shp, bl.Shape().Shp))
+		}
+		if al.NRecvPrjns() != bl.NRecvPrjns() {
+			errs = append(errs, fmt.Sprintf("layer: %v number of receiving prjns: %d vs. %d", al.Name(), al.NRecvPrjns(), bl.NRecvPrjns()))
+		}
+		for pi := 0; pi < al.NRecvPrjns() && pi < bl.NRecvPrjns(); pi++ {
+			ap := al.RecvPrjn(pi)
+			bp := bl.RecvPrjn(pi)
+			if ap.SendLay().Name() != bp.SendLay().Name() {
+				errs = append(errs, fmt.Sprintf("layer: %v prjn: %d from: %v vs. %v", al.Name(), pi, ap.SendLay().Name(), bp.SendLay().Name()))
+				continue
+			}
+			ap.SynVals(&av, "Wt")
+			bp.SynVals(&bv, "Wt")
+			if len(av) != len(bv) {
+				errs = append(errs, fmt.Sprintf("prjn: %v number of connections: %d vs. %d", ap.Name(), len(av), len(bv)))
+			}
+		}
+	}
+	for li := 0; li < b.NLayers(); li++ {
+		bl := b.Layer(li)
+		if _, err := a.LayerByNameTry(bl.Name()); err != nil {
+			errs = append(errs, fmt.Sprintf("layer: %v not found in: %v", bl.Name(), a.Name()))
+		}
+	}
+	return topoErr(a.Name(), b.Name(), errs)
+}
+
+// VerifyWtsTopology checks that the weights read from a file (e.g., via
+// weights.NetReadJSON) are consistent with the topology of the network:
+// all of the layers and receiving projections in the file exist in the
+// network, the unit indexes are within range of the layer sizes, and the
+// number of connections in each projection is the same.
+// Returns an error listing every mismatch, or nil if consistent.
+func VerifyWtsTopology(net emer.Network, nw *weights.Network) error {
+	var errs []string
+	var vals []float32
+	for li := range nw.Layers {
+		lw := &nw.Layers[li]
+		ly, err := net.LayerByNameTry(lw.Layer)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("layer: %v not found", lw.Layer))
+			continue
+		}
+		used := make(map[emer.Prjn]bool)
+		for pi := range lw.Prjns {
+			pw := &lw.Prjns[pi]
+			var pj emer.Prjn
+			for _, rp := range *ly.RecvPrjns() {
+				if !used[rp] && rp.SendLay().Name() == pw.From {
+					pj = rp
+					break
+				}
+			}
+			if pj == nil {
+				errs = append(errs, fmt.Sprintf("layer: %v receiving prjn from: %v not found", lw.Layer, pw.From))
+				continue
+			}
+			used[pj] = true
+			nr := ly.Shape().Len()
+			ns := pj.SendLay().Shape().Len()
+			ncon := 0
+			badIdx := false
+			for ri := range pw.Rs {
+				rw := &pw.Rs[ri]
+				ncon += len(rw.Si)
+				if rw.Ri < 0 || rw.Ri >= nr {
+					badIdx = true
+				}
+				for _, si := range rw.Si {
+					if si < 0 || si >= ns {
+						badIdx = true
+					}
+				}
+			}
+			if badIdx {
+				errs = append(errs, fmt.Sprintf("prjn: %v unit indexes out of range for recv size: %d send size: %d", pj.Name(), nr, ns))
+			}
+			if err := pj.SynVals(&vals, "Wt"); err == nil && len(vals) != ncon {
+				errs = append(errs, fmt.Sprintf("prjn: %v number of connections: %d in network vs. %d in weights", pj.Name(), len(vals), ncon))
+			}
+		}
+	}
+	return topoErr(net.Name(), nw.Network, errs)
+}
+
+// ReadWtsJSONVerify reads network weights in the JSON format written by
+// WriteWtsJSON from given reader, and sets them in the network if
+// VerifyWtsTopology finds no mismatches -- otherwise the network is not
+// modified and the mismatch error is returned.
+func ReadWtsJSONVerify(net emer.Network, r io.Reader) error {
+	nw, err := weights.NetReadJSON(r)
+	if err != nil {
+		return err
+	}
+	if err := VerifyWtsTopology(net, nw); err != nil {
+		return err
+	}
+	return net.SetWts(nw)
+}
+
+// topoErr returns an error listing all the mismatches, if any
+func topoErr(anm, bnm string, errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("emeranal: topology mismatch between: %v and: %v:\n\t%v", anm, bnm, strings.Join(errs, "\n\t"))
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/emer/emergent/internal/emertest"
+	"github.com/emer/emergent/prjn"
+)
+
+// topoNet returns a built In -> Hid network, with given Hid shape,
+// and the projection pattern
+func topoNet(name string, hidShape []int, pat prjn.Pattern) *emertest.Network {
+	net := emertest.NewNetwork(name)
+	in := net.AddLayer("In", []int{2, 3}, emer.Input)
+	hid := net.AddLayer("Hid", hidShape, emer.Hidden)
+	net.ConnectLayers(in, hid, pat, emer.Forward)
+	net.Build()
+	return net
+}
+
+func TestVerifyTopology(t *testing.T) {
+	a := topoNet("A", []int{2, 2}, prjn.NewFull())
+	if err := emeranal.VerifyTopology(a, topoNet("B", []int{2, 2}, prjn.NewFull())); err != nil {
+		t.Errorf("same topology: %v", err)
+	}
+
+	// each case should report only the given mismatches
+	cases := []struct {
+		name string
+		b    *emertest.Network
+		want []string
+	}{
+		{"layers", func() *emertest.Network {
+			b := topoNet("B", []int{2, 2}, prjn.NewFull())
+			b.AddLayer("Out", []int{2, 2}, emer.Target).Build()
+			return b
+		}(), []string{"number of layers: 2 vs. 3", "layer: Out not found in: A"}},
+		{"shape", topoNet("B", []int{4, 1}, prjn.NewFull()), []string{"layer: Hid shape: [2 2] vs. [4 1]"}},
+		{"ncons", topoNet("B", []int{2, 2}, prjn.NewOneToOne()), []string{"prjn: InToHid number of connections: 24 vs. 4"}},
+		{"sender", func() *emertest.Network {
+			b := emertest.NewNetwork("B")
+			b.AddLayer("In", []int{2, 3}, emer.Input)
+			hid := b.AddLayer("Hid", []int{2, 2}, emer.Hidden)
+			b.ConnectLayers(hid, hid, prjn.NewFull(), emer.Lateral)
+			b.Build()
+			return b
+		}(), []string{"layer: Hid prjn: 0 from: In vs. Hid"}},
+		{"nprjns", func() *emertest.Network {
+			b := topoNet("B", []int{2, 2}, prjn.NewFull())
+			b.ConnectLayers(b.Lays[1], b.Lays[1], prjn.NewFull(), emer.Lateral)
+			b.Build()
+			return b
+		}(), []string{"layer: Hid number of receiving prjns: 1 vs. 2"}},
+	}
+	for _, c := range cases {
+		err := emeranal.VerifyTopology(a, c.b)
+		if err == nil {
+			t.Errorf("%s: expected mismatch error", c.name)
+			continue
+		}
+		msg := err.Error()
+		if !strings.HasPrefix(msg, "emeranal: topology mismatch between: A and: B:") {
+			t.Errorf("%s: error: %v", c.name, msg)
+		}
+		if n := strings.Count(msg, "\n\t"); n != len(c.want) {
+			t.Errorf("%s: %d mismatches != %d: %v", c.name, n, len(c.want), msg)
+		}
+		for _, w := range c.want {
+			if !strings.Contains(msg, "\n\t"+w) {
+				t.Errorf("%s: error does not report: %q: %v", c.name, w, msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/emer/emergent/emer"
+	"github.com/goki/ki/kit"
+)
+
+// LayerTypeByName returns the LayerType for given type name, e.g., as read
+// from a configuration file, returning an error if the name is not a known
+// layer type.  Types defined by specialized algorithms that extend
+// KiT_LayerType (via kit.Enums.AddEnumExt) are also included, so the
+// result may need to be converted to that extended type.
+func LayerTypeByName(nm string) (emer.LayerType, error) {
+	val, err := EnumValByName(emer.KiT_LayerType, nm)
+	if err != nil {
+		return emer.Hidden, fmt.Errorf("emeranal.LayerTypeByName: layer type named: %v not found", nm)
+	}
+	return emer.LayerType(val), nil
+}
+
+// PrjnTypeByName returns the PrjnType for given type name, e.g., as read
+// from a configuration file, returning an error if the name is not a known
+// projection type.  Types defined by specialized algorithms that extend
+// KiT_PrjnType (e.g., the DeepLeabra projection types) are also included,
+// so the result may need to be converted to that extended type.
+func PrjnTypeByName(nm string) (emer.PrjnType, error) {
+	val, err := EnumValByName(emer.KiT_PrjnType, nm)
+	if err != nil {
+		return emer.Forward, fmt.Errorf("emeranal.PrjnTypeByName: projection type named: %v not found", nm)
+	}
+	return emer.PrjnType(val), nil
+}
+
+// EnumValByName returns the int64 value of the enum value with given name,
+// searching the given enum type and any enum types registered in kit.Enums
+// that extend it.  Returns an error if not found.
+func EnumValByName(et reflect.Type, nm string) (int64, error) {
+	for _, ev := range kit.Enums.TypeValues(et, false) {
+		if ev.Name == nm {
+			return ev.Value, nil
+		}
+	}
+	for enm, typ := range kit.Enums.Enums {
+		if typ == et || kit.Enums.ParType(enm) != et {
+			continue
+		}
+		for _, ev := range kit.Enums.Values(enm, false) {
+			if ev.Name == nm {
+				return ev.Value, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("emeranal.EnumValByName: enum value named: %v not found in type: %v", nm, et.Name())
+}
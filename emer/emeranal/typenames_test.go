@@ -0,0 +1,90 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/goki/ki/kit"
+)
+
+// testLayerType and testPrjnType extend the emer types, in the way that
+// specialized algorithms (e.g., DeepLeabra) do
+type testLayerType emer.LayerType
+
+const (
+	Super testLayerType = testLayerType(emer.LayerTypeN) + iota
+	testLayerTypeN
+)
+
+var KiT_testLayerType = kit.Enums.AddEnumExt(emer.KiT_LayerType, testLayerTypeN, kit.NotBitFlag, nil)
+
+func (i testLayerType) String() string {
+	if i == Super {
+		return "Super"
+	}
+	return emer.LayerType(i).String()
+}
+
+type testPrjnType emer.PrjnType
+
+const (
+	BurstCtxt testPrjnType = testPrjnType(emer.PrjnTypeN) + iota
+	BurstTRC
+	testPrjnTypeN
+)
+
+var KiT_testPrjnType = kit.Enums.AddEnumExt(emer.KiT_PrjnType, testPrjnTypeN, kit.NotBitFlag, nil)
+
+func (i testPrjnType) String() string {
+	switch i {
+	case BurstCtxt:
+		return "BurstCtxt"
+	case BurstTRC:
+		return "BurstTRC"
+	}
+	return emer.PrjnType(i).String()
+}
+
+func TestLayerTypeByName(t *testing.T) {
+	cases := []struct {
+		nm   string
+		want emer.LayerType
+		ok   bool
+	}{
+		{"Target", emer.Target, true},
+		{"Hidden", emer.Hidden, true},
+		{"Super", emer.LayerType(Super), true},
+		{"Targ", emer.Hidden, false},
+		{"", emer.Hidden, false},
+	}
+	for _, c := range cases {
+		typ, err := emeranal.LayerTypeByName(c.nm)
+		if (err == nil) != c.ok || typ != c.want {
+			t.Errorf("LayerTypeByName(%q): %v, %v; want %v, ok: %v", c.nm, typ, err, c.want, c.ok)
+		}
+	}
+}
+
+func TestPrjnTypeByName(t *testing.T) {
+	cases := []struct {
+		nm   string
+		want emer.PrjnType
+		ok   bool
+	}{
+		{"Back", emer.Back, true},
+		{"Forward", emer.Forward, true},
+		{"BurstTRC", emer.PrjnType(BurstTRC), true},
+		{"Bak", emer.Forward, false},
+	}
+	for _, c := range cases {
+		typ, err := emeranal.PrjnTypeByName(c.nm)
+		if (err == nil) != c.ok || typ != c.want {
+			t.Errorf("PrjnTypeByName(%q): %v, %v; want %v, ok: %v", c.nm, typ, err, c.want, c.ok)
+		}
+	}
+}
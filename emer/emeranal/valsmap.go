@@ -0,0 +1,29 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etensor"
+)
+
+// LayerValsMap returns a map from layer name to a tensor with the current
+// values of given unit variable (e.g., Act) for each unit in the layer, in
+// the shape of the layer, for all layers in the network.  The tensors are
+// copies, so they are not affected by the ongoing simulation, and modifying
+// them does not affect the network.  Returns error if the variable is not valid.
+func LayerValsMap(net emer.Network, varNm string) (map[string]*etensor.Float32, error) {
+	nl := net.NLayers()
+	vm := make(map[string]*etensor.Float32, nl)
+	for li := 0; li < nl; li++ {
+		ly := net.Layer(li)
+		tsr := etensor.NewFloat32Shape(ly.Shape(), nil)
+		if err := ly.UnitValsTensor(tsr, varNm); err != nil {
+			return nil, err
+		}
+		vm[ly.Name()] = tsr
+	}
+	return vm, nil
+}
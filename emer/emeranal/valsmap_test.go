@@ -0,0 +1,43 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/emer/emergent/emer/emeranal"
+)
+
+func TestLayerValsMap(t *testing.T) {
+	net, _ := testNet()
+	act := net.Lays[1].Vals["Act"]
+	copy(act, []float32{0.1, 0.2, 0.3, 0.4})
+	vm, err := emeranal.LayerValsMap(net, "Act")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vm) != 2 || vm["In"] == nil || vm["Hid"] == nil {
+		t.Fatalf("layers in map: %v", vm)
+	}
+	hid := vm["Hid"]
+	if !reflect.DeepEqual(hid.Shapes(), []int{2, 2}) || !reflect.DeepEqual(vm["In"].Shapes(), []int{2, 3}) {
+		t.Errorf("shapes: Hid: %v In: %v", hid.Shapes(), vm["In"].Shapes())
+	}
+	if !reflect.DeepEqual(hid.Values, []float32{0.1, 0.2, 0.3, 0.4}) || hid.Value([]int{1, 0}) != 0.3 {
+		t.Errorf("Hid values: %v", hid.Values)
+	}
+	act[0] = 0.9
+	if hid.Values[0] != 0.1 {
+		t.Errorf("snapshot changed with the network: %v", hid.Values)
+	}
+	hid.Values[1] = 0.8
+	if act[1] != 0.2 {
+		t.Errorf("network changed with the snapshot: %v", act)
+	}
+	if _, err := emeranal.LayerValsMap(net, "Nope"); err == nil {
+		t.Errorf("expected error for invalid variable")
+	}
+}
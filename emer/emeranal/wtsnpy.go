@@ -0,0 +1,57 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"fmt"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/npy"
+	"github.com/emer/etable/etensor"
+)
+
+// PrjnSynsTensor returns a dense [recv][send] tensor of the values of given
+// synapse variable in the projection, with the units of each layer in 1D order,
+// and 0 for pairs of units that are not connected.
+func PrjnSynsTensor(pj emer.Prjn, varNm string) (*etensor.Float32, error) {
+	ns := pj.SendLay().Shape().Len()
+	nr := pj.RecvLay().Shape().Len()
+	tsr := etensor.NewFloat32([]int{nr, ns}, nil, []string{"Recv", "Send"})
+	err := SynsFunc(pj, varNm, func(si, ri int, val float32) {
+		tsr.Values[ri*ns+si] = val
+	})
+	return tsr, err
+}
+
+// SaveWtsNpy saves the weights (Wt) of the projection to given file
+// in the NumPy .npy format, as a dense [recv][send] float32 matrix,
+// with 0 for pairs of units that are not connected (see PrjnSynsTensor).
+// This can be loaded in Python using numpy.load.
+func SaveWtsNpy(pj emer.Prjn, fname string) error {
+	tsr, err := PrjnSynsTensor(pj, "Wt")
+	if err != nil {
+		return err
+	}
+	return npy.SaveTensor(tsr, fname)
+}
+
+// OpenWtsNpy sets the weights (Wt) of the projection from given file in
+// the NumPy .npy format, which must be a [recv][send] float32 matrix
+// as saved by SaveWtsNpy.  Only the weights of existing connections are set:
+// values for pairs of units that are not connected are ignored.
+func OpenWtsNpy(pj emer.Prjn, fname string) error {
+	tsr, err := npy.OpenTensor(fname)
+	if err != nil {
+		return err
+	}
+	ns := pj.SendLay().Shape().Len()
+	nr := pj.RecvLay().Shape().Len()
+	if tsr.NumDims() != 2 || tsr.Dim(0) != nr || tsr.Dim(1) != ns {
+		return fmt.Errorf("emeranal.OpenWtsNpy: prjn: %v shape: %v in file: %v is not [recv: %d, send: %d]", pj.Name(), tsr.Shapes(), fname, nr, ns)
+	}
+	return SynsFunc(pj, "Wt", func(si, ri int, wt float32) {
+		pj.SetSynVal("Wt", si, ri, tsr.Values[ri*ns+si])
+	})
+}
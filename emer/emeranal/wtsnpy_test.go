@@ -0,0 +1,71 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/emer/emergent/npy"
+	"github.com/emer/etable/etensor"
+)
+
+func TestWtsNpy(t *testing.T) {
+	dir := t.TempDir()
+	a, afull, aone := connNet()
+	b, bfull, bone := connNet()
+	emeranal.InitWtsFn(aone, func(si, ri int) float32 { return float32(si) / 10 })
+	if emeranal.EqualWts(a, b) {
+		t.Fatalf("networks should start out different")
+	}
+	apjs := []emer.Prjn{afull, aone}
+	bpjs := []emer.Prjn{bfull, bone}
+	for i, pj := range apjs {
+		fn := filepath.Join(dir, pj.Name()+".npy")
+		if err := emeranal.SaveWtsNpy(pj, fn); err != nil {
+			t.Fatal(err)
+		}
+		if err := emeranal.OpenWtsNpy(bpjs[i], fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !emeranal.EqualWts(a, b) {
+		t.Errorf("weights not equal after round trip")
+	}
+
+	// the file is dense [recv][send], with 0 for unconnected pairs
+	fn := filepath.Join(dir, aone.Name()+".npy")
+	tsr, err := npy.OpenTensor(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tsr.Dim(0) != 4 || tsr.Dim(1) != 6 || tsr.Value([]int{2, 3}) != 0.3 || tsr.Value([]int{2, 2}) != 0 {
+		t.Errorf("OneToOne tensor: %v", tsr)
+	}
+	// values for unconnected pairs are ignored on open
+	tsr.Set([]int{0, 0}, 9)
+	if err := npy.SaveTensor(tsr, fn); err != nil {
+		t.Fatal(err)
+	}
+	if err := emeranal.OpenWtsNpy(bone, fn); err != nil {
+		t.Fatal(err)
+	}
+	if !emeranal.EqualWts(a, b) {
+		t.Errorf("value for an unconnected pair changed the weights")
+	}
+
+	wfn := filepath.Join(dir, "wrong.npy")
+	if err := npy.SaveTensor(etensor.NewFloat32([]int{6, 4}, nil, nil), wfn); err != nil {
+		t.Fatal(err)
+	}
+	if err := emeranal.OpenWtsNpy(bfull, wfn); err == nil {
+		t.Errorf("expected error for [send, recv] shape")
+	}
+	if err := emeranal.OpenWtsNpy(bfull, filepath.Join(dir, "none.npy")); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/emer/emergent/emer"
+)
+
+// WtsSnapshot is an in-memory copy of the network weights (and any other
+// state saved by WriteWtsJSON), which can be restored to the network later.
+// Typically Save is called right after the network is built and initialized,
+// and Restore is then used instead of re-initializing, so that every run
+// (e.g., in a parameter search) starts from exactly the same initial weights.
+type WtsSnapshot struct {
+	Wts []byte `view:"-" desc:"the saved weights, in the JSON format written by WriteWtsJSON"`
+}
+
+// Save saves the current weights of the network into the snapshot,
+// replacing anything previously saved.
+func (ws *WtsSnapshot) Save(net emer.Network) {
+	var b bytes.Buffer
+	net.WriteWtsJSON(&b)
+	ws.Wts = b.Bytes()
+}
+
+// Restore sets the weights of the network to those in the snapshot.
+// Returns error if nothing has been saved yet, or from ReadWtsJSON.
+func (ws *WtsSnapshot) Restore(net emer.Network) error {
+	if len(ws.Wts) == 0 {
+		return errors.New("emeranal.WtsSnapshot: Restore called before Save")
+	}
+	return net.ReadWtsJSON(bytes.NewReader(ws.Wts))
+}
+
+// IsSaved returns true if weights have been saved in the snapshot
+func (ws *WtsSnapshot) IsSaved() bool {
+	return len(ws.Wts) > 0
+}
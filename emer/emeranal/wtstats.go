@@ -0,0 +1,170 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chewxy/math32"
+	"github.com/emer/emergent/emer"
+)
+
+// DWtNorm returns the L2 norm (square root of the sum of squares) of the
+// DWt weight change values of all synapses in the projection.
+// This must be called after the DWt has been computed and before it is
+// applied and reset in the WtFmDWt step.
+// Returns 0 if the projection does not have a DWt synapse variable.
+func DWtNorm(pj emer.Prjn) float32 {
+	var dwts []float32
+	if err := pj.SynVals(&dwts, "DWt"); err != nil {
+		return 0
+	}
+	ss := float32(0)
+	for _, dw := range dwts {
+		ss += dw * dw
+	}
+	return math32.Sqrt(ss)
+}
+
+// DWtNorms returns the DWtNorm for each projection in the network,
+// keyed by projection name.  Useful for monitoring the overall magnitude
+// of learning over time, to detect vanishing or exploding weight changes.
+func DWtNorms(net emer.Network) map[string]float32 {
+	pjs := NetPrjns(net)
+	nrms := make(map[string]float32, len(pjs))
+	for _, pj := range pjs {
+		nrms[pj.Name()] = DWtNorm(pj)
+	}
+	return nrms
+}
+
+// PrjnWtStats holds summary statistics of the weight (Wt) values
+// in a projection, for monitoring the health of the weights during
+// training, e.g., to detect weights saturating or collapsing.
+type PrjnWtStats struct {
+	SendLay  string  `desc:"name of the sending layer"`
+	RecvLay  string  `desc:"name of the receiving layer"`
+	PrjnType string  `desc:"type of projection, from PrjnTypeName"`
+	N        int     `desc:"number of synapses"`
+	Min      float32 `desc:"minimum weight"`
+	Max      float32 `desc:"maximum weight"`
+	Mean     float32 `desc:"mean weight"`
+	Std      float32 `desc:"standard deviation of weights"`
+	PctZero  float32 `desc:"proportion of weights (0-1) whose magnitude is below the zero threshold passed to Compute"`
+}
+
+// Compute computes the stats over given weight values, in a single pass,
+// counting weights with absolute value below zeroThr in PctZero.
+func (ws *PrjnWtStats) Compute(wts []float32, zeroThr float32) {
+	ws.N = len(wts)
+	ws.Min, ws.Max, ws.Mean, ws.Std, ws.PctZero = 0, 0, 0, 0, 0
+	if ws.N == 0 {
+		return
+	}
+	ws.Min = math32.MaxFloat32
+	ws.Max = -math32.MaxFloat32
+	sum := 0.0
+	ssq := 0.0
+	nz := 0
+	for _, wt := range wts {
+		ws.Min = math32.Min(ws.Min, wt)
+		ws.Max = math32.Max(ws.Max, wt)
+		sum += float64(wt)
+		ssq += float64(wt) * float64(wt)
+		if math32.Abs(wt) < zeroThr {
+			nz++
+		}
+	}
+	n := float64(ws.N)
+	mean := sum / n
+	vr := ssq/n - mean*mean
+	if vr < 0 { // rounding
+		vr = 0
+	}
+	ws.Mean = float32(mean)
+	ws.Std = math32.Sqrt(float32(vr))
+	ws.PctZero = float32(nz) / float32(ws.N)
+}
+
+// WtStats returns the PrjnWtStats for each projection in the network,
+// in the order of NetPrjns, with weights of magnitude below zeroThr
+// (e.g., .01) counted in PctZero.  Projections without a Wt variable have N = 0.
+func WtStats(net emer.Network, zeroThr float32) []PrjnWtStats {
+	pjs := NetPrjns(net)
+	sts := make([]PrjnWtStats, len(pjs))
+	var wts []float32
+	for i, pj := range pjs {
+		ws := &sts[i]
+		ws.SendLay = pj.SendLay().Name()
+		ws.RecvLay = pj.RecvLay().Name()
+		ws.PrjnType = pj.PrjnTypeName()
+		if err := pj.SynVals(&wts, "Wt"); err != nil {
+			continue
+		}
+		ws.Compute(wts, zeroThr)
+	}
+	return sts
+}
+
+// WtStatsString returns the WtStats for the network formatted as an
+// aligned table, with one line per projection.
+func WtStatsString(net emer.Network, zeroThr float32) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Send\tRecv\tType\tN\tMin\tMax\tMean\tStd\tPctZero\t\n")
+	for _, ws := range WtStats(net, zeroThr) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%.4g\t%.4g\t%.4g\t%.4g\t%.4g\t\n", ws.SendLay, ws.RecvLay, ws.PrjnType, ws.N, ws.Min, ws.Max, ws.Mean, ws.Std, ws.PctZero)
+	}
+	tw.Flush()
+	return b.String()
+}
+
+// WtHistogram returns the counts of the weight (Wt) values in the projection
+// falling into each of nBins equal-sized bins spanning the range min..max,
+// with values outside of the range counted in the first or last bin.
+// If min == max == 0, the range is set from the actual min and max weights.
+// Returns nil if the projection does not have a Wt synapse variable.
+func WtHistogram(pj emer.Prjn, nBins int, min, max float32) []int {
+	var wts []float32
+	if err := pj.SynVals(&wts, "Wt"); err != nil || nBins < 1 {
+		return nil
+	}
+	if min == 0 && max == 0 && len(wts) > 0 {
+		min, max = wts[0], wts[0]
+		for _, wt := range wts {
+			min = math32.Min(min, wt)
+			max = math32.Max(max, wt)
+		}
+	}
+	hist := make([]int, nBins)
+	rng := max - min
+	for _, wt := range wts {
+		bi := 0
+		if rng > 0 {
+			bi = int(float32(nBins) * (wt - min) / rng)
+		}
+		if bi < 0 {
+			bi = 0
+		} else if bi >= nBins {
+			bi = nBins - 1
+		}
+		hist[bi]++
+	}
+	return hist
+}
+
+// WtHistograms returns the WtHistogram for each projection in the network,
+// keyed by projection name, using the same bins for all projections
+// (or the range of each projection separately if min == max == 0).
+func WtHistograms(net emer.Network, nBins int, min, max float32) map[string][]int {
+	pjs := NetPrjns(net)
+	hists := make(map[string][]int, len(pjs))
+	for _, pj := range pjs {
+		hists[pj.Name()] = WtHistogram(pj, nBins, min, max)
+	}
+	return hists
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package emeranal_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/emergent/emer/emeranal"
+	"github.com/emer/emergent/internal/emertest"
+)
+
+func TestPrjnWtStats(t *testing.T) {
+	tests := []struct {
+		name    string
+		wts     []float32
+		zeroThr float32
+		want    emeranal.PrjnWtStats
+	}{
+		{"empty", nil, .01, emeranal.PrjnWtStats{}},
+		{"const", []float32{.5, .5, .5, .5}, .01, emeranal.PrjnWtStats{N: 4, Min: .5, Max: .5, Mean: .5}},
+		{"mixed", []float32{-1, 0, .005, 1}, .01, emeranal.PrjnWtStats{N: 4, Min: -1, Max: 1, Mean: .00125, Std: 0.7071101, PctZero: .5}},
+		{"negative", []float32{-.5, -.005, .005, .5}, .01, emeranal.PrjnWtStats{N: 4, Min: -.5, Max: .5, Mean: 0, Std: 0.3535711, PctZero: .5}},
+		{"thresh", []float32{-.5, -.005, .005, .5}, .6, emeranal.PrjnWtStats{N: 4, Min: -.5, Max: .5, Mean: 0, Std: 0.3535711, PctZero: 1}},
+	}
+	for _, tt := range tests {
+		var ws emeranal.PrjnWtStats
+		ws.Compute(tt.wts, tt.zeroThr)
+		w := tt.want
+		if ws.N != w.N || ws.Min != w.Min || ws.Max != w.Max || ws.PctZero != w.PctZero ||
+			math.Abs(float64(ws.Mean-w.Mean)) > 1e-6 || math.Abs(float64(ws.Std-w.Std)) > 1e-5 {
+			t.Errorf("%s: got %+v, want %+v", tt.name, ws, w)
+		}
+	}
+}
+
+func TestWtStats(t *testing.T) {
+	net, pj := testNet()
+	pj.Wt[1] = -pj.Wt[1] // -1/24: not counted as zero
+	sts := emeranal.WtStats(net, .01)
+	if len(sts) != 1 {
+		t.Fatalf("got %d stats, want 1", len(sts))
+	}
+	ws := sts[0]
+	if ws.SendLay != "In" || ws.RecvLay != "Hid" || ws.PrjnType != "Forward" || ws.N != 24 {
+		t.Errorf("got %+v", ws)
+	}
+	if ws.Min != -1.0/24 || ws.Max != 23.0/24 || ws.PctZero != 1.0/24 {
+		t.Errorf("min: %g max: %g pctzero: %g", ws.Min, ws.Max, ws.PctZero)
+	}
+	if math.Abs(float64(ws.Mean)-(11.5*24-2)/(24*24)) > 1e-6 {
+		t.Errorf("mean: %g", ws.Mean)
+	}
+}
+
+func TestDWtNorm(t *testing.T) {
+	net, full, one := connNet()
+	if nrm := emeranal.DWtNorm(full); nrm != 0 {
+		t.Errorf("zero DWt norm: %g", nrm)
+	}
+	full.SetSynVal("DWt", 0, 0, 3)
+	full.SetSynVal("DWt", 1, 2, -4)
+	if nrm := emeranal.DWtNorm(full); math.Abs(float64(nrm)-5) > 1e-6 {
+		t.Errorf("full DWt norm: %g != 5", nrm)
+	}
+	if err := one.SetSynVal("DWt", 2, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	nrms := emeranal.DWtNorms(net)
+	if len(nrms) != 2 || nrms[full.Name()] != 5 || nrms[one.Name()] != 2 {
+		t.Errorf("DWtNorms: %v", nrms)
+	}
+	one.(*emertest.Prjn).Vars = []string{"Wt"}
+	if nrm := emeranal.DWtNorm(one); nrm != 0 {
+		t.Errorf("norm without DWt var should be 0: %g", nrm)
+	}
+}
+
+func TestWtHistogram(t *testing.T) {
+	net, pj := testNet() // weights 0, 1/24 .. 23/24
+	eq := func(a, b []int) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+	cases := []struct {
+		name     string
+		nBins    int
+		min, max float32
+		want     []int
+	}{
+		// 6/24 = .25 is on the edge, and goes in the upper bin
+		{"bins", 4, 0, 1, []int{6, 6, 6, 6}},
+		// values below .25 and at or above .75 go in the end bins
+		{"clip", 2, .25, .75, []int{12, 12}},
+		// range 0..23/24, so 23/24 is clipped into the last bin
+		{"auto", 2, 0, 0, []int{12, 12}},
+		{"one", 1, 0, 1, []int{24}},
+		{"zero", 0, 0, 1, nil},
+		{"neg", -1, 0, 1, nil},
+	}
+	for _, c := range cases {
+		if h := emeranal.WtHistogram(pj, c.nBins, c.min, c.max); !eq(h, c.want) {
+			t.Errorf("%s: %v != %v", c.name, h, c.want)
+		}
+	}
+	pj.Wt[0] = -5 // stays in bin 0
+	pj.Wt[1] = 5  // moves from bin 0 to 3
+	if h := emeranal.WtHistogram(pj, 4, 0, 1); !eq(h, []int{5, 6, 6, 7}) {
+		t.Errorf("out of range values should be counted in the end bins: %v", h)
+	}
+	hs := emeranal.WtHistograms(net, 4, 0, 1)
+	if len(hs) != 1 || !eq(hs[pj.Name()], []int{5, 6, 6, 7}) {
+		t.Errorf("WtHistograms: %v", hs)
+	}
+	pj.Vars = []string{"DWt"}
+	if h := emeranal.WtHistogram(pj, 4, 0, 1); h != nil {
+		t.Errorf("no Wt var should give nil: %v", h)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package history records the activation patterns of selected layers over
+the most recent trials of a simulation, in a fixed-size circular (ring)
+buffer, so that they can be replayed or analyzed without storing the
+entire history of a long run.
+*/
+package history
+
+import (
+	"fmt"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// History records the values of a unit variable (Act by default) for
+// a set of layers on each trial, in a ring buffer holding the most recent
+// BufLen trials.  Call Attach to set the network and layers, and then
+// Record once per trial, at the point where the values are to be
+// captured (e.g., at the end of the trial).
+type History struct {
+	Net    emer.Network                  `view:"-" desc:"the network to record from"`
+	Layers []string                      `desc:"names of the layers to record"`
+	Var    string                        `def:"Act" desc:"unit variable to record"`
+	BufLen int                           `desc:"number of trials held in the buffer"`
+	NTot   int                           `inactive:"+" desc:"total number of trials recorded since Attach -- the buffer holds the last min(NTot, BufLen)"`
+	Bufs   map[string][]*etensor.Float32 `view:"-" desc:"ring buffer of values for each layer"`
+}
+
+// Attach sets the network, layers and buffer length to record, and
+// allocates the buffers, clearing any previous history.
+// Returns an error if a layer is not found, in which case the
+// History is left unchanged.
+func (hs *History) Attach(net emer.Network, layers []string, bufLen int) error {
+	if bufLen < 1 {
+		return fmt.Errorf("history.Attach: bufLen: %d must be > 0", bufLen)
+	}
+	bufs := make(map[string][]*etensor.Float32, len(layers))
+	for _, lnm := range layers {
+		ly, err := net.LayerByNameTry(lnm)
+		if err != nil {
+			return err
+		}
+		buf := make([]*etensor.Float32, bufLen)
+		for i := range buf {
+			buf[i] = etensor.NewFloat32Shape(ly.Shape(), nil)
+		}
+		bufs[lnm] = buf
+	}
+	if hs.Var == "" {
+		hs.Var = "Act"
+	}
+	hs.Net = net
+	hs.Layers = layers
+	hs.BufLen = bufLen
+	hs.NTot = 0
+	hs.Bufs = bufs
+	return nil
+}
+
+// Len returns the number of trials currently held in the buffer
+func (hs *History) Len() int {
+	if hs.NTot < hs.BufLen {
+		return hs.NTot
+	}
+	return hs.BufLen
+}
+
+// Record records the current values for all the layers, overwriting
+// the oldest trial in the buffer if it is full.
+// Returns error if Attach has not been called, or if Var is not a valid
+// unit variable.
+func (hs *History) Record() error {
+	if hs.Net == nil || hs.BufLen < 1 {
+		return fmt.Errorf("history.Record: Attach must be called first")
+	}
+	idx := hs.NTot % hs.BufLen
+	for _, lnm := range hs.Layers {
+		ly, err := hs.Net.LayerByNameTry(lnm)
+		if err != nil {
+			return err
+		}
+		if err := ly.UnitValsTensor(hs.Bufs[lnm][idx], hs.Var); err != nil {
+			return err
+		}
+	}
+	hs.NTot++
+	return nil
+}
+
+// Get returns a copy of the values recorded for given layer trialOffset
+// trials ago: 0 = the most recent trial, up to Len()-1 for the oldest.
+// Returns an error if the layer is not recorded or the offset is out of range.
+func (hs *History) Get(layerName string, trialOffset int) (*etensor.Float32, error) {
+	buf, has := hs.Bufs[layerName]
+	if !has {
+		return nil, fmt.Errorf("history.Get: layer named: %v is not recorded", layerName)
+	}
+	if trialOffset < 0 || trialOffset >= hs.Len() {
+		return nil, fmt.Errorf("history.Get: trialOffset: %d is out of range for the: %d trials in the buffer", trialOffset, hs.Len())
+	}
+	idx := (hs.NTot - 1 - trialOffset) % hs.BufLen
+	return buf[idx].Clone().(*etensor.Float32), nil
+}
+
+// Table returns the contents of the buffer as an etable.Table, with one
+// row per trial from oldest to newest, a Trial column with the number of
+// the trial since Attach, and a column for each layer with the layer's shape.
+func (hs *History) Table() *etable.Table {
+	sc := etable.Schema{{Name: "Trial", Type: etensor.INT64}}
+	for _, lnm := range hs.Layers {
+		shp := hs.Bufs[lnm][0].ShapeObj()
+		sc = append(sc, etable.Column{Name: lnm, Type: etensor.FLOAT32, CellShape: shp.Shp, DimNames: shp.Nms})
+	}
+	n := hs.Len()
+	dt := etable.New(sc, n)
+	dt.SetMetaData("name", "History_"+hs.Var)
+	for row := 0; row < n; row++ {
+		off := n - 1 - row
+		dt.SetCellFloatIdx(0, row, float64(hs.NTot-1-off))
+		for li, lnm := range hs.Layers {
+			dt.SetCellTensorIdx(1+li, row, hs.Bufs[lnm][(hs.NTot-1-off)%hs.BufLen])
+		}
+	}
+	return dt
+}
+
+// ExportCSV saves the full contents of the buffer (see Table) to
+// given file in CSV format.
+func (hs *History) ExportCSV(fname gi.FileName) error {
+	return hs.Table().SaveCSV(fname, etable.Comma, etable.Headers)
+}
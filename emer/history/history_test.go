@@ -0,0 +1,106 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package history
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/internal/emertest"
+	"github.com/goki/gi/gi"
+)
+
+func testNet() (*emertest.Network, *emertest.Layer) {
+	net := emertest.NewNetwork("Test")
+	net.AddLayer("In", []int{2, 3}, emer.Input)
+	hid := net.AddLayer("Hid", []int{2, 2}, emer.Hidden)
+	net.Build()
+	return net, hid
+}
+
+func TestNotAttached(t *testing.T) {
+	hs := &History{}
+	if err := hs.Record(); err == nil {
+		t.Errorf("expected error for Record before Attach")
+	}
+	if _, err := hs.Get("Hid", 0); err == nil {
+		t.Errorf("expected error for Get before Attach")
+	}
+	if hs.Len() != 0 || hs.Table().Rows != 0 {
+		t.Errorf("expected empty history")
+	}
+	net, _ := testNet()
+	if err := hs.Attach(net, []string{"Hid"}, 0); err == nil {
+		t.Errorf("expected error for bufLen 0")
+	}
+	if err := hs.Attach(net, []string{"Hid", "Nope"}, 2); err == nil {
+		t.Errorf("expected error for unknown layer")
+	}
+	if err := hs.Record(); err == nil {
+		t.Errorf("expected error for Record after failed Attach")
+	}
+}
+
+func TestRingBuffer(t *testing.T) {
+	net, hid := testNet()
+	hs := &History{}
+	if err := hs.Attach(net, []string{"Hid", "In"}, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hs.Get("Hid", 0); err == nil {
+		t.Errorf("expected error on empty buffer")
+	}
+	for trl := 0; trl < 7; trl++ { // wraps around twice
+		hid.Vals["Act"][0] = float32(trl)
+		if err := hs.Record(); err != nil {
+			t.Fatal(err)
+		}
+		wantLen := trl + 1
+		if wantLen > 3 {
+			wantLen = 3
+		}
+		if hs.Len() != wantLen {
+			t.Errorf("trial %d: Len: %d, want %d", trl, hs.Len(), wantLen)
+		}
+	}
+	if hs.NTot != 7 {
+		t.Errorf("NTot: %d", hs.NTot)
+	}
+	for off := 0; off < 3; off++ {
+		tsr, err := hs.Get("Hid", off)
+		if err != nil || tsr.Values[0] != float32(6-off) {
+			t.Errorf("offset %d: got %v %v, want %d", off, tsr.Values, err, 6-off)
+		}
+	}
+	if _, err := hs.Get("Hid", 3); err == nil {
+		t.Errorf("expected error for offset past the oldest trial")
+	}
+	tsr, _ := hs.Get("Hid", 0)
+	tsr.Values[0] = 100
+	if tsr, _ = hs.Get("Hid", 0); tsr.Values[0] != 6 {
+		t.Errorf("Get does not return a copy")
+	}
+
+	dt := hs.Table()
+	if dt.Rows != 3 {
+		t.Fatalf("Table rows: %d", dt.Rows)
+	}
+	for row := 0; row < 3; row++ {
+		if dt.CellFloat("Trial", row) != float64(4+row) || dt.CellTensor("Hid", row).FloatVal1D(0) != float64(4+row) {
+			t.Errorf("Table row %d: trial %g val %g", row, dt.CellFloat("Trial", row), dt.CellTensor("Hid", row).FloatVal1D(0))
+		}
+	}
+	fn := filepath.Join(t.TempDir(), "hist.csv")
+	if err := hs.ExportCSV(gi.FileName(fn)); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadFile(fn)
+	if lines := strings.Split(strings.TrimSpace(string(b)), "\n"); len(lines) != 4 {
+		t.Errorf("CSV: %d lines:\n%s", len(lines), b)
+	}
+}
@@ -41,7 +41,9 @@ model will only work within a particular paradigm of environments following
 specific conventions.
 
 See e.g., env.FixedTable for particular implementation of a fixed Table
-of patterns, for one example of a widely-used paradigm.
+of patterns, for one example of a widely-used paradigm.  For patterns
+that are generated on the fly instead of stored in a Table, env.StreamEnv
+presents the patterns from a Stream (e.g., patgen.VocabStream).
 
 Typically each specific implementation of this Env interface will have
 multiple parameters etc that can be modified to control env behavior --
@@ -0,0 +1,132 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"fmt"
+
+	"github.com/emer/etable/etensor"
+)
+
+// Stream is a source of patterns that are generated lazily, one trial at
+// a time, e.g., for procedurally-generated or very large datasets that
+// should not be materialized into an etable.Table.  See StreamEnv for
+// an Env that presents the patterns from a Stream.
+type Stream interface {
+	// Next returns the patterns for the next trial, keyed by element name,
+	// or false if the stream is exhausted (finite streams only).
+	Next() (inputs map[string]*etensor.Float32, ok bool)
+
+	// Reset returns the stream to its initial state, so that the
+	// same sequence of patterns is generated again.
+	Reset()
+
+	// Elements returns the names and shapes of the patterns generated
+	Elements() Elements
+}
+
+// StreamEnv is an Env that presents the patterns generated by a Stream,
+// with standard Run / Epoch / Trial counters.  An epoch ends when the
+// Stream is exhausted (after which it is Reset), or after Trial.Max trials
+// if that is > 0, which is required to have epochs for an infinite stream.
+// It also records the outer loop of Run as provided by the model.
+type StreamEnv struct {
+	Nm         string                      `desc:"name of this environment"`
+	Dsc        string                      `desc:"description of this environment"`
+	Stream     Stream                      `desc:"the source of patterns"`
+	ResetEpoch bool                        `desc:"if true, Reset the Stream at the start of each epoch ending at Trial.Max, so the same patterns are presented every epoch -- otherwise the stream just continues"`
+	Run        Ctr                         `view:"inline" desc:"current run of model as provided during Init"`
+	Epoch      Ctr                         `view:"inline" desc:"number of epochs completed"`
+	Trial      Ctr                         `view:"inline" desc:"current trial within the epoch -- set Max > 0 for a fixed number of trials per epoch"`
+	Cur        map[string]*etensor.Float32 `view:"-" desc:"the current patterns returned by the Stream"`
+}
+
+func (se *StreamEnv) Name() string { return se.Nm }
+func (se *StreamEnv) Desc() string { return se.Dsc }
+
+func (se *StreamEnv) Validate() error {
+	if se.Stream == nil {
+		return fmt.Errorf("env.StreamEnv: %v has no Stream set", se.Nm)
+	}
+	return nil
+}
+
+// Init initializes the counters and Resets the Stream
+func (se *StreamEnv) Init(run int) {
+	se.Run.Scale = Run
+	se.Epoch.Scale = Epoch
+	se.Trial.Scale = Trial
+	se.Run.Init()
+	se.Epoch.Init()
+	se.Trial.Init()
+	se.Run.Cur = run
+	se.Trial.Cur = -1 // init state -- key so that first Step() = 0
+	se.Stream.Reset()
+	se.Cur = nil
+}
+
+// Step gets the next patterns from the Stream, returning false
+// if the Stream has no patterns at all.
+func (se *StreamEnv) Step() bool {
+	se.Epoch.Same() // good idea to just reset all non-inner-most counters at start
+
+	epc := false
+	if se.Trial.Incr() { // if true, hit max, reset to 0
+		epc = true
+		if se.ResetEpoch {
+			se.Stream.Reset()
+		}
+	}
+	pats, ok := se.Stream.Next()
+	if !ok { // exhausted: start over
+		se.Stream.Reset()
+		pats, ok = se.Stream.Next()
+		epc = se.Trial.Cur > 0 || epc
+		se.Trial.Cur = 0
+	}
+	if epc {
+		se.Epoch.Incr()
+	}
+	se.Cur = pats
+	return ok
+}
+
+func (se *StreamEnv) Counters() []TimeScales {
+	return []TimeScales{Run, Epoch, Trial}
+}
+
+func (se *StreamEnv) Counter(scale TimeScales) (cur, prv int, chg bool) {
+	switch scale {
+	case Run:
+		return se.Run.Query()
+	case Epoch:
+		return se.Epoch.Query()
+	case Trial:
+		return se.Trial.Query()
+	}
+	return -1, -1, false
+}
+
+func (se *StreamEnv) States() Elements {
+	return se.Stream.Elements()
+}
+
+func (se *StreamEnv) State(element string) etensor.Tensor {
+	if tsr, ok := se.Cur[element]; ok {
+		return tsr
+	}
+	return nil
+}
+
+func (se *StreamEnv) Actions() Elements {
+	return nil
+}
+
+func (se *StreamEnv) Action(element string, input etensor.Tensor) {
+	// nop
+}
+
+// Compile-time check that implements Env interface
+var _ Env = (*StreamEnv)(nil)
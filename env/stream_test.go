@@ -0,0 +1,128 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+// countStream generates a 1-unit Input pattern with the number of
+// trials since Reset, for N trials (0 = infinite), or none if Empty
+type countStream struct {
+	N     int
+	Cur   int
+	Empty bool
+}
+
+func (cs *countStream) Next() (map[string]*etensor.Float32, bool) {
+	if cs.Empty || (cs.N > 0 && cs.Cur >= cs.N) {
+		return nil, false
+	}
+	tsr := etensor.NewFloat32([]int{1}, nil, nil)
+	tsr.Values[0] = float32(cs.Cur)
+	cs.Cur++
+	return map[string]*etensor.Float32{"Input": tsr}, true
+}
+
+func (cs *countStream) Reset() {
+	cs.Cur = 0
+}
+
+func (cs *countStream) Elements() Elements {
+	return Elements{{Name: "Input", Shape: []int{1}}}
+}
+
+// step Steps the env and returns the Input value and the Epoch and Trial counters
+func step(t *testing.T, se *StreamEnv) (val float32, ep, trl int, epChg bool) {
+	t.Helper()
+	if !se.Step() {
+		t.Fatalf("Step returned false")
+	}
+	val = se.State("Input").(*etensor.Float32).Values[0]
+	ep, _, epChg = se.Counter(Epoch)
+	trl, _, _ = se.Counter(Trial)
+	return
+}
+
+func TestStreamEnvFinite(t *testing.T) {
+	cs := &countStream{N: 3}
+	se := &StreamEnv{Nm: "Test", Stream: cs}
+	if err := se.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	se.Init(2)
+	if run, _, _ := se.Counter(Run); run != 2 {
+		t.Errorf("run: %d", run)
+	}
+	if se.State("Input") != nil {
+		t.Errorf("State should be nil before the first Step")
+	}
+	// the epoch ends when the stream is exhausted, and it starts over
+	wants := []struct {
+		val     float32
+		ep, trl int
+		chg     bool
+	}{{0, 0, 0, false}, {1, 0, 1, false}, {2, 0, 2, false}, {0, 1, 0, true}, {1, 1, 1, false}}
+	for i, w := range wants {
+		val, ep, trl, chg := step(t, se)
+		if val != w.val || ep != w.ep || trl != w.trl || chg != w.chg {
+			t.Errorf("step %d: val: %g epoch: %d (chg %v) trial: %d, want: %+v", i, val, ep, chg, trl, w)
+		}
+	}
+	if se.State("NoSuch") != nil {
+		t.Errorf("State for unknown element should be nil")
+	}
+	if els := se.States(); len(els) != 1 || els[0].Name != "Input" {
+		t.Errorf("States: %v", els)
+	}
+	if cur, prv, chg := se.Counter(Block); cur != -1 || prv != -1 || chg {
+		t.Errorf("unsupported counter: %d %d %v", cur, prv, chg)
+	}
+
+	se.Init(3) // Init resets the stream and counters
+	if val, ep, trl, _ := step(t, se); val != 0 || ep != 0 || trl != 0 {
+		t.Errorf("after Init: val: %g epoch: %d trial: %d", val, ep, trl)
+	}
+}
+
+func TestStreamEnvTrialMax(t *testing.T) {
+	cs := &countStream{}
+	se := &StreamEnv{Nm: "Test", Stream: cs}
+	se.Trial.Max = 2
+	se.Init(0)
+	// infinite stream continues across epochs
+	for i := 0; i < 5; i++ {
+		val, ep, trl, _ := step(t, se)
+		if val != float32(i) || ep != i/2 || trl != i%2 {
+			t.Errorf("step %d: val: %g epoch: %d trial: %d", i, val, ep, trl)
+		}
+	}
+	// ResetEpoch replays the same patterns each epoch
+	se.ResetEpoch = true
+	se.Init(0)
+	for i := 0; i < 5; i++ {
+		val, ep, trl, _ := step(t, se)
+		if val != float32(i%2) || ep != i/2 || trl != i%2 {
+			t.Errorf("ResetEpoch step %d: val: %g epoch: %d trial: %d", i, val, ep, trl)
+		}
+	}
+}
+
+func TestStreamEnvEmpty(t *testing.T) {
+	se := &StreamEnv{Nm: "Test"}
+	if err := se.Validate(); err == nil {
+		t.Errorf("expected error for no Stream")
+	}
+	se.Stream = &countStream{Empty: true}
+	se.Init(0)
+	if se.Step() {
+		t.Errorf("Step should return false for a stream with no patterns")
+	}
+	if se.State("Input") != nil {
+		t.Errorf("State should be nil for a stream with no patterns")
+	}
+}
@@ -7,5 +7,7 @@
 // *  RndParams: specifies parameters for random number generation according to various distributions
 //    used e.g., for initializing random weights and generating random noise in neurons
 // *  Permute*: basic convenience methods calling rand.Shuffle on e.g., []int slice
+// *  Seeds: a set of random seeds, e.g., one per run, and SubSeed for deriving
+//    reproducible seeds for separate random sequences (e.g., per layer or projection)
 //
 package erand
@@ -0,0 +1,48 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package erand
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Seeds is a set of random seeds, typically used one per Run,
+// so that each run is reproducible, and can be replicated individually.
+type Seeds []int64
+
+// Init allocates given number of seeds and initializes them to new
+// random values using NewSeeds.
+func (rs *Seeds) Init(n int) {
+	*rs = make([]int64, n)
+	rs.NewSeeds()
+}
+
+// NewSeeds sets a new set of seeds based on the current time
+func (rs *Seeds) NewSeeds() {
+	rn := time.Now().UnixNano()
+	for i := range *rs {
+		(*rs)[i] = SubSeed(rn, i)
+	}
+}
+
+// Set sets the global math/rand seed to the seed at given index,
+// which is used by all of the functions in this package.
+func (rs *Seeds) Set(idx int) {
+	rand.Seed((*rs)[idx])
+}
+
+// SubSeed returns a new seed deterministically derived from the given seed
+// and index, e.g., to give each layer or projection (by index) its own
+// independent, reproducible random sequence, via rand.New(rand.NewSource(seed)),
+// which then does not depend on the order in which they are processed
+// (e.g., in parallel threads).  Uses the SplitMix64 mixing function, so that
+// nearby seeds and indexes produce unrelated sub-seeds.
+func SubSeed(seed int64, idx int) int64 {
+	z := uint64(seed) + uint64(idx+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package erand
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSubSeed(t *testing.T) {
+	// SubSeed(seed, i) is the i-th output of SplitMix64 seeded with seed
+	for i, want := range []uint64{0xe220a8397b1dcdaf, 0x6e789e6aa1b965f4} {
+		if ss := SubSeed(0, i); uint64(ss) != want {
+			t.Errorf("SubSeed(0, %d): %x != %x", i, uint64(ss), want)
+		}
+	}
+	seen := map[int64]bool{}
+	for _, seed := range []int64{0, 1, 2, -1, 42} {
+		for idx := 0; idx < 100; idx++ {
+			ss := SubSeed(seed, idx)
+			if ss != SubSeed(seed, idx) {
+				t.Errorf("SubSeed(%d, %d) is not deterministic", seed, idx)
+			}
+			if seen[ss] {
+				t.Errorf("SubSeed(%d, %d): %d is not distinct", seed, idx, ss)
+			}
+			seen[ss] = true
+		}
+	}
+}
+
+func TestSeeds(t *testing.T) {
+	var rs Seeds
+	rs.Init(3)
+	if len(rs) != 3 || rs[0] == rs[1] || rs[1] == rs[2] {
+		t.Errorf("Init seeds: %v", rs)
+	}
+	seq := func(idx int) []int64 {
+		rs.Set(idx)
+		s := make([]int64, 5)
+		for i := range s {
+			s[i] = rand.Int63()
+		}
+		return s
+	}
+	s0, s1 := seq(0), seq(1)
+	for i, v := range seq(0) {
+		if v != s0[i] {
+			t.Fatalf("Set(0) does not reproduce the same sequence: %v vs. %v", seq(0), s0)
+		}
+	}
+	if s0[0] == s1[0] {
+		t.Errorf("different seeds give the same sequence: %v", s0)
+	}
+}
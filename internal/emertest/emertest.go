@@ -0,0 +1,546 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package emertest provides a minimal in-memory implementation of the emer
+Network, Layer and Prjn interfaces, for testing code that is written
+generically in terms of those interfaces (e.g., in emer/emeranal and
+emer/checkpoint).  It is internal, for use by the tests in this module
+only.  It has no algorithm: unit values are just stored in a map of named
+slices (Act, ActM, ActP, Targ) that tests can set directly, and projections
+store a dense Wt and DWt for each (send, recv) pair, with the connectivity
+given by the prjn.Pattern.
+*/
+package emertest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/emergent/relpos"
+	"github.com/emer/emergent/weights"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+	"github.com/goki/mat32"
+)
+
+// UnitVars are the unit variables of each Layer
+var UnitVars = []string{"Act", "ActM", "ActP", "Targ"}
+
+// SynVars are the synapse variables of each Prjn
+var SynVars = []string{"Wt", "DWt"}
+
+// Network is a minimal emer.Network
+type Network struct {
+	Nm   string
+	Lays []*Layer
+}
+
+var _ emer.Network = (*Network)(nil)
+
+// NewNetwork returns a new empty Network with given name
+func NewNetwork(name string) *Network {
+	return &Network{Nm: name}
+}
+
+// AddLayer adds a new layer with given name, shape and type
+func (nt *Network) AddLayer(name string, shape []int, typ emer.LayerType) *Layer {
+	ly := &Layer{Nm: name, Typ: typ, Idx: len(nt.Lays), Vals: map[string][]float32{}}
+	ly.Shp.SetShape(shape, nil, nil)
+	nt.Lays = append(nt.Lays, ly)
+	return ly
+}
+
+// Build allocates the unit values and synapses of all layers and
+// projections, which must be done after they are all configured.
+func (nt *Network) Build() {
+	for _, ly := range nt.Lays {
+		ly.Build()
+		for _, pj := range ly.Rcv {
+			pj.(*Prjn).Build()
+		}
+	}
+}
+
+func (nt *Network) InitName(net emer.Network, name string) { nt.Nm = name }
+func (nt *Network) Name() string                           { return nt.Nm }
+func (nt *Network) Label() string                          { return nt.Nm }
+func (nt *Network) NLayers() int                           { return len(nt.Lays) }
+func (nt *Network) Layer(idx int) emer.Layer               { return nt.Lays[idx] }
+
+func (nt *Network) LayerByName(name string) emer.Layer {
+	for _, ly := range nt.Lays {
+		if ly.Nm == name {
+			return ly
+		}
+	}
+	return nil
+}
+
+func (nt *Network) LayerByNameTry(name string) (emer.Layer, error) {
+	ly := nt.LayerByName(name)
+	if ly == nil {
+		return nil, fmt.Errorf("emertest: layer named: %v not found", name)
+	}
+	return ly, nil
+}
+
+func (nt *Network) Defaults()     {}
+func (nt *Network) UpdateParams() {}
+
+func (nt *Network) ApplyParams(pars *params.Sheet, setMsg bool) (bool, error) {
+	applied := false
+	var rerr error
+	for _, ly := range nt.Lays {
+		app, err := ly.ApplyParams(pars, setMsg)
+		if app {
+			applied = true
+		}
+		if err != nil {
+			rerr = err
+		}
+	}
+	return applied, rerr
+}
+
+func (nt *Network) NonDefaultParams() string { return "" }
+func (nt *Network) AllParams() string        { return "" }
+
+// WriteWtsJSON writes the weights of all connected synapses in the
+// standard weights.Network JSON format
+func (nt *Network) WriteWtsJSON(w io.Writer) {
+	nw := &weights.Network{Network: nt.Nm}
+	for _, ly := range nt.Lays {
+		lw := weights.Layer{Layer: ly.Nm}
+		for _, p := range ly.Rcv {
+			pj := p.(*Prjn)
+			pw := weights.Prjn{From: pj.Send.Nm}
+			ns := pj.Send.Shp.Len()
+			nr := pj.Recv.Shp.Len()
+			for ri := 0; ri < nr; ri++ {
+				rw := weights.Recv{Ri: ri}
+				for si := 0; si < ns; si++ {
+					if pj.Con[ri*ns+si] {
+						rw.Si = append(rw.Si, si)
+						rw.Wt = append(rw.Wt, pj.Wt[ri*ns+si])
+					}
+				}
+				rw.N = len(rw.Si)
+				pw.Rs = append(pw.Rs, rw)
+			}
+			lw.Prjns = append(lw.Prjns, pw)
+		}
+		nw.Layers = append(nw.Layers, lw)
+	}
+	b, _ := json.Marshal(nw)
+	w.Write(b)
+}
+
+func (nt *Network) ReadWtsJSON(r io.Reader) error {
+	nw, err := weights.NetReadJSON(r)
+	if err != nil {
+		return err
+	}
+	return nt.SetWts(nw)
+}
+
+func (nt *Network) SetWts(nw *weights.Network) error {
+	for li := range nw.Layers {
+		lw := &nw.Layers[li]
+		ly, err := nt.LayerByNameTry(lw.Layer)
+		if err != nil {
+			return err
+		}
+		if err := ly.SetWts(lw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (nt *Network) SaveWtsJSON(filename gi.FileName) error {
+	fp, err := os.Create(string(filename))
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	bw := bufio.NewWriter(fp)
+	nt.WriteWtsJSON(bw)
+	return bw.Flush()
+}
+
+func (nt *Network) OpenWtsJSON(filename gi.FileName) error {
+	fp, err := os.Open(string(filename))
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	return nt.ReadWtsJSON(bufio.NewReader(fp))
+}
+
+func (nt *Network) NewLayer() emer.Layer { return &Layer{Vals: map[string][]float32{}} }
+func (nt *Network) NewPrjn() emer.Prjn   { return &Prjn{} }
+
+func (nt *Network) ConnectLayerNames(send, recv string, pat prjn.Pattern, typ emer.PrjnType) (rlay, slay emer.Layer, pj emer.Prjn, err error) {
+	rlay, err = nt.LayerByNameTry(recv)
+	if err != nil {
+		return
+	}
+	slay, err = nt.LayerByNameTry(send)
+	if err != nil {
+		return
+	}
+	pj = nt.ConnectLayers(slay, rlay, pat, typ)
+	return
+}
+
+func (nt *Network) ConnectLayers(send, recv emer.Layer, pat prjn.Pattern, typ emer.PrjnType) emer.Prjn {
+	pj := &Prjn{Send: send.(*Layer), Recv: recv.(*Layer), Pat: pat, Typ: typ}
+	pj.Recv.Rcv = append(pj.Recv.Rcv, pj)
+	pj.Send.Snd = append(pj.Send.Snd, pj)
+	return pj
+}
+
+func (nt *Network) Bounds() (min, max mat32.Vec3)                       { return }
+func (nt *Network) VarRange(varNm string) (min, max float32, err error) { return }
+
+// Layer is a minimal emer.Layer, with unit values stored in Vals
+type Layer struct {
+	Nm   string
+	Cls  string
+	Off  bool
+	Shp  etensor.Shape
+	Typ  emer.LayerType
+	Thr  int
+	Idx  int
+	Rel  relpos.Rel
+	Ps   mat32.Vec3
+	Rcv  emer.Prjns
+	Snd  emer.Prjns
+	Vals map[string][]float32 `desc:"values for each of the UnitVars, in 1D order of the layer Shp"`
+}
+
+var _ emer.Layer = (*Layer)(nil)
+
+// Build allocates the Vals for the UnitVars
+func (ly *Layer) Build() error {
+	n := ly.Shp.Len()
+	for _, vnm := range UnitVars {
+		ly.Vals[vnm] = make([]float32, n)
+	}
+	return nil
+}
+
+func (ly *Layer) TypeName() string                                       { return "Layer" }
+func (ly *Layer) Class() string                                          { return ly.Cls }
+func (ly *Layer) Name() string                                           { return ly.Nm }
+func (ly *Layer) InitName(lay emer.Layer, name string, net emer.Network) { ly.Nm = name }
+func (ly *Layer) Label() string                                          { return ly.Nm }
+func (ly *Layer) SetName(nm string)                                      { ly.Nm = nm }
+func (ly *Layer) SetClass(cls string)                                    { ly.Cls = cls }
+func (ly *Layer) IsOff() bool                                            { return ly.Off }
+func (ly *Layer) SetOff(off bool)                                        { ly.Off = off }
+func (ly *Layer) Shape() *etensor.Shape                                  { return &ly.Shp }
+func (ly *Layer) Is2D() bool                                             { return ly.Shp.NumDims() == 2 }
+func (ly *Layer) Is4D() bool                                             { return ly.Shp.NumDims() == 4 }
+func (ly *Layer) Idx4DFrom2D(x, y int) ([]int, bool)                     { return nil, false }
+func (ly *Layer) Type() emer.LayerType                                   { return ly.Typ }
+func (ly *Layer) SetType(typ emer.LayerType)                             { ly.Typ = typ }
+func (ly *Layer) Thread() int                                            { return ly.Thr }
+func (ly *Layer) SetThread(thr int)                                      { ly.Thr = thr }
+func (ly *Layer) RelPos() relpos.Rel                                     { return ly.Rel }
+func (ly *Layer) SetRelPos(rel relpos.Rel)                               { ly.Rel = rel }
+func (ly *Layer) Pos() mat32.Vec3                                        { return ly.Ps }
+func (ly *Layer) SetPos(pos mat32.Vec3)                                  { ly.Ps = pos }
+func (ly *Layer) Size() mat32.Vec2                                       { return mat32.Vec2{} }
+func (ly *Layer) Index() int                                             { return ly.Idx }
+func (ly *Layer) SetIndex(idx int)                                       { ly.Idx = idx }
+func (ly *Layer) UnitVarNames() []string                                 { return UnitVars }
+func (ly *Layer) UnitVarProps() map[string]string                        { return nil }
+
+func (ly *Layer) Config(shape []int, typ emer.LayerType) {
+	ly.Shp.SetShape(shape, nil, nil)
+	ly.Typ = typ
+}
+
+func (ly *Layer) vals(varNm string) ([]float32, error) {
+	vl, ok := ly.Vals[varNm]
+	if !ok {
+		return nil, fmt.Errorf("emertest: layer: %v unit variable named: %v not found", ly.Nm, varNm)
+	}
+	return vl, nil
+}
+
+func (ly *Layer) UnitVals(vals *[]float32, varNm string) error {
+	vl, err := ly.vals(varNm)
+	if err != nil {
+		return err
+	}
+	if len(*vals) < len(vl) {
+		*vals = make([]float32, len(vl))
+	}
+	copy(*vals, vl)
+	return nil
+}
+
+func (ly *Layer) UnitValsTensor(tsr etensor.Tensor, varNm string) error {
+	vl, err := ly.vals(varNm)
+	if err != nil {
+		return err
+	}
+	if tsr.Len() != len(vl) {
+		tsr.SetShape(ly.Shp.Shp, nil, ly.Shp.Nms)
+	}
+	for i, v := range vl {
+		tsr.SetFloat1D(i, float64(v))
+	}
+	return nil
+}
+
+func (ly *Layer) UnitVal(varNm string, idx []int) float32 {
+	v, err := ly.UnitValTry(varNm, idx)
+	if err != nil {
+		return float32(math.NaN())
+	}
+	return v
+}
+
+func (ly *Layer) UnitValTry(varNm string, idx []int) (float32, error) {
+	return ly.UnitVal1DTry(varNm, ly.Shp.Offset(idx))
+}
+
+func (ly *Layer) UnitVal1D(varNm string, idx int) float32 {
+	v, err := ly.UnitVal1DTry(varNm, idx)
+	if err != nil {
+		return float32(math.NaN())
+	}
+	return v
+}
+
+func (ly *Layer) UnitVal1DTry(varNm string, idx int) (float32, error) {
+	vl, err := ly.vals(varNm)
+	if err != nil {
+		return 0, err
+	}
+	if idx < 0 || idx >= len(vl) {
+		return 0, fmt.Errorf("emertest: layer: %v unit index: %d out of range", ly.Nm, idx)
+	}
+	return vl[idx], nil
+}
+
+func (ly *Layer) RecvPrjns() *emer.Prjns     { return &ly.Rcv }
+func (ly *Layer) NRecvPrjns() int            { return len(ly.Rcv) }
+func (ly *Layer) RecvPrjn(idx int) emer.Prjn { return ly.Rcv[idx] }
+func (ly *Layer) SendPrjns() *emer.Prjns     { return &ly.Snd }
+func (ly *Layer) NSendPrjns() int            { return len(ly.Snd) }
+func (ly *Layer) SendPrjn(idx int) emer.Prjn { return ly.Snd[idx] }
+
+func (ly *Layer) RecvPrjnVals(vals *[]float32, varNm string, sendLay emer.Layer, sendIdx1D int, prjnType string) error {
+	return fmt.Errorf("emertest: RecvPrjnVals not supported")
+}
+
+func (ly *Layer) SendPrjnVals(vals *[]float32, varNm string, recvLay emer.Layer, recvIdx1D int, prjnType string) error {
+	return fmt.Errorf("emertest: SendPrjnVals not supported")
+}
+
+func (ly *Layer) Defaults()     {}
+func (ly *Layer) UpdateParams() {}
+
+func (ly *Layer) ApplyParams(pars *params.Sheet, setMsg bool) (bool, error) {
+	return pars.Apply(ly, setMsg)
+}
+
+func (ly *Layer) NonDefaultParams() string            { return "" }
+func (ly *Layer) AllParams() string                   { return "" }
+func (ly *Layer) WriteWtsJSON(w io.Writer, depth int) {}
+func (ly *Layer) ReadWtsJSON(r io.Reader) error {
+	return fmt.Errorf("emertest: Layer.ReadWtsJSON not supported")
+}
+
+func (ly *Layer) SetWts(lw *weights.Layer) error {
+	for pi := range lw.Prjns {
+		pw := &lw.Prjns[pi]
+		pj, err := ly.Rcv.SendNameTry(pw.From)
+		if err != nil {
+			return err
+		}
+		if err := pj.SetWts(pw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ly *Layer) VarRange(varNm string) (min, max float32, err error) { return }
+
+// Prjn is a minimal emer.Prjn, with dense Wt and DWt values for every
+// (send, recv) pair, indexed as ri * nSend + si, of which only those
+// where Con is true are actual synapses.
+type Prjn struct {
+	Send *Layer
+	Recv *Layer
+	Pat  prjn.Pattern
+	Typ  emer.PrjnType
+	Cls  string
+	Off  bool
+	Con  []bool
+	Wt   []float32
+	DWt  []float32
+	Vars []string `desc:"synapse variable names, if different from SynVars, e.g., to test handling of a missing variable -- only Wt and DWt are supported"`
+}
+
+var _ emer.Prjn = (*Prjn)(nil)
+
+// Build allocates the synapses according to the Pat connectivity
+func (pj *Prjn) Build() error {
+	_, _, cons := pj.Pat.Connect(&pj.Send.Shp, &pj.Recv.Shp, pj.Send == pj.Recv)
+	n := cons.Len()
+	pj.Con = make([]bool, n)
+	pj.Wt = make([]float32, n)
+	pj.DWt = make([]float32, n)
+	for i := range pj.Con {
+		pj.Con[i] = cons.Value1D(i)
+	}
+	return nil
+}
+
+func (pj *Prjn) TypeName() string                                                   { return "Prjn" }
+func (pj *Prjn) Class() string                                                      { return pj.Cls }
+func (pj *Prjn) Name() string                                                       { return pj.Send.Nm + "To" + pj.Recv.Nm }
+func (pj *Prjn) Init(prj emer.Prjn)                                                 {}
+func (pj *Prjn) RecvLay() emer.Layer                                                { return pj.Recv }
+func (pj *Prjn) SendLay() emer.Layer                                                { return pj.Send }
+func (pj *Prjn) Pattern() prjn.Pattern                                              { return pj.Pat }
+func (pj *Prjn) SetPattern(pat prjn.Pattern)                                        { pj.Pat = pat }
+func (pj *Prjn) Type() emer.PrjnType                                                { return pj.Typ }
+func (pj *Prjn) SetType(typ emer.PrjnType)                                          { pj.Typ = typ }
+func (pj *Prjn) PrjnTypeName() string                                               { return pj.Typ.String() }
+func (pj *Prjn) Connect(send, recv emer.Layer, pat prjn.Pattern, typ emer.PrjnType) {}
+func (pj *Prjn) SetClass(cls string)                                                { pj.Cls = cls }
+func (pj *Prjn) Label() string                                                      { return pj.Name() }
+func (pj *Prjn) IsOff() bool                                                        { return pj.Off || pj.Send.Off || pj.Recv.Off }
+func (pj *Prjn) SetOff(off bool)                                                    { pj.Off = off }
+func (pj *Prjn) SynVarProps() map[string]string                                     { return nil }
+
+func (pj *Prjn) SynVarNames() []string {
+	if pj.Vars != nil {
+		return pj.Vars
+	}
+	return SynVars
+}
+
+func (pj *Prjn) vals(varNm string) ([]float32, error) {
+	has := false
+	for _, vn := range pj.SynVarNames() {
+		if vn == varNm {
+			has = true
+		}
+	}
+	switch {
+	case !has:
+	case varNm == "Wt":
+		return pj.Wt, nil
+	case varNm == "DWt":
+		return pj.DWt, nil
+	}
+	return nil, fmt.Errorf("emertest: prjn: %v synapse variable named: %v not found", pj.Name(), varNm)
+}
+
+// synIdx returns the index of the synapse from si to ri, or an error
+// if the indexes are out of range or there is no such synapse
+func (pj *Prjn) synIdx(sidx, ridx int) (int, error) {
+	ns := pj.Send.Shp.Len()
+	if sidx < 0 || sidx >= ns || ridx < 0 || ridx >= pj.Recv.Shp.Len() {
+		return 0, fmt.Errorf("emertest: prjn: %v synapse index: %d, %d out of range", pj.Name(), sidx, ridx)
+	}
+	idx := ridx*ns + sidx
+	if !pj.Con[idx] {
+		return 0, fmt.Errorf("emertest: prjn: %v no synapse from: %d to: %d", pj.Name(), sidx, ridx)
+	}
+	return idx, nil
+}
+
+// SynVals returns the values of all synapses, in sender-major order
+func (pj *Prjn) SynVals(vals *[]float32, varNm string) error {
+	vl, err := pj.vals(varNm)
+	if err != nil {
+		return err
+	}
+	*vals = (*vals)[:0]
+	ns := pj.Send.Shp.Len()
+	nr := pj.Recv.Shp.Len()
+	for si := 0; si < ns; si++ {
+		for ri := 0; ri < nr; ri++ {
+			if pj.Con[ri*ns+si] {
+				*vals = append(*vals, vl[ri*ns+si])
+			}
+		}
+	}
+	return nil
+}
+
+func (pj *Prjn) SynVal(varNm string, sidx, ridx int) float32 {
+	v, err := pj.SynValTry(varNm, sidx, ridx)
+	if err != nil {
+		return float32(math.NaN())
+	}
+	return v
+}
+
+func (pj *Prjn) SynValTry(varNm string, sidx, ridx int) (float32, error) {
+	vl, err := pj.vals(varNm)
+	if err != nil {
+		return 0, err
+	}
+	idx, err := pj.synIdx(sidx, ridx)
+	if err != nil {
+		return 0, err
+	}
+	return vl[idx], nil
+}
+
+func (pj *Prjn) SetSynVal(varNm string, sidx, ridx int, val float32) error {
+	vl, err := pj.vals(varNm)
+	if err != nil {
+		return err
+	}
+	idx, err := pj.synIdx(sidx, ridx)
+	if err != nil {
+		return err
+	}
+	vl[idx] = val
+	return nil
+}
+
+func (pj *Prjn) Defaults()     {}
+func (pj *Prjn) UpdateParams() {}
+
+func (pj *Prjn) ApplyParams(pars *params.Sheet, setMsg bool) (bool, error) {
+	return pars.Apply(pj, setMsg)
+}
+
+func (pj *Prjn) NonDefaultParams() string            { return "" }
+func (pj *Prjn) AllParams() string                   { return "" }
+func (pj *Prjn) WriteWtsJSON(w io.Writer, depth int) {}
+func (pj *Prjn) ReadWtsJSON(r io.Reader) error {
+	return fmt.Errorf("emertest: Prjn.ReadWtsJSON not supported")
+}
+
+func (pj *Prjn) SetWts(pw *weights.Prjn) error {
+	for _, rw := range pw.Rs {
+		for i, si := range rw.Si {
+			if err := pj.SetSynVal("Wt", si, rw.Ri, rw.Wt[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package npy reads and writes float32 arrays in the NumPy .npy file format
+(version 1.0), for exchanging data such as weight matrices with Python
+(numpy.load / numpy.save).  Only little-endian float32 ('<f4') data in
+C (row-major) order is supported.
+*/
+package npy
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/emer/etable/etensor"
+)
+
+// Magic is the magic string at the start of every .npy file
+const Magic = "\x93NUMPY"
+
+// MaxVals is the maximum number of values that Read accepts (1 GB of
+// float32 data), so that a corrupt header cannot cause a huge allocation
+const MaxVals = 1 << 28
+
+// Write writes given float32 values with given shape to the writer in .npy format.
+// The number of values must equal the product of the shape dimensions.
+func Write(w io.Writer, shape []int, vals []float32) error {
+	n := 1
+	for _, d := range shape {
+		n *= d
+	}
+	if n != len(vals) {
+		return fmt.Errorf("npy.Write: shape: %v does not match number of values: %d", shape, len(vals))
+	}
+	var shp string
+	switch len(shape) {
+	case 0:
+		shp = "()"
+	case 1:
+		shp = fmt.Sprintf("(%d,)", shape[0])
+	default:
+		ss := make([]string, len(shape))
+		for i, d := range shape {
+			ss[i] = strconv.Itoa(d)
+		}
+		shp = "(" + strings.Join(ss, ", ") + ")"
+	}
+	hdr := fmt.Sprintf("{'descr': '<f4', 'fortran_order': False, 'shape': %s, }", shp)
+	// total header size incl magic (6), version (2), and len (2) must be a multiple of 64, ending in \n
+	tot := 10 + len(hdr) + 1
+	if pad := tot % 64; pad != 0 {
+		hdr += strings.Repeat(" ", 64-pad)
+	}
+	hdr += "\n"
+	bw := bufio.NewWriter(w)
+	bw.WriteString(Magic)
+	bw.Write([]byte{1, 0})
+	binary.Write(bw, binary.LittleEndian, uint16(len(hdr)))
+	bw.WriteString(hdr)
+	b := make([]byte, 4)
+	for _, v := range vals {
+		binary.LittleEndian.PutUint32(b, math.Float32bits(v))
+		bw.Write(b)
+	}
+	return bw.Flush()
+}
+
+// Read reads float32 values in .npy format from the reader, returning the
+// shape and values.  Returns an error if the data is not in the supported format.
+func Read(r io.Reader) (shape []int, vals []float32, err error) {
+	br := bufio.NewReader(r)
+	pre := make([]byte, 10)
+	if _, err = io.ReadFull(br, pre); err != nil {
+		return
+	}
+	if string(pre[:6]) != Magic {
+		err = errors.New("npy.Read: not a .npy file")
+		return
+	}
+	var hlen int
+	switch pre[6] {
+	case 1:
+		hlen = int(binary.LittleEndian.Uint16(pre[8:10]))
+	case 2, 3:
+		ext := make([]byte, 2)
+		if _, err = io.ReadFull(br, ext); err != nil {
+			return
+		}
+		hlen = int(binary.LittleEndian.Uint32(append(pre[8:10], ext...)))
+	default:
+		err = fmt.Errorf("npy.Read: unsupported version: %d.%d", pre[6], pre[7])
+		return
+	}
+	hdr := make([]byte, hlen)
+	if _, err = io.ReadFull(br, hdr); err != nil {
+		return
+	}
+	shape, err = parseHeader(string(hdr))
+	if err != nil {
+		return
+	}
+	n := 1
+	for _, d := range shape {
+		if d != 0 && n > MaxVals/d {
+			err = fmt.Errorf("npy.Read: shape: %v has more than MaxVals: %d values", shape, MaxVals)
+			return
+		}
+		n *= d
+	}
+	// the buffer only grows as data is actually read, so a truncated file
+	// with a large shape in the header does not allocate the full size
+	data, err := ioutil.ReadAll(io.LimitReader(br, 4*int64(n)))
+	if err == nil && len(data) != 4*n {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		err = fmt.Errorf("npy.Read: reading %d values: %v", n, err)
+		return
+	}
+	vals = make([]float32, n)
+	for i := range vals {
+		vals[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return
+}
+
+// parseHeader parses the header dictionary, returning the shape
+func parseHeader(hdr string) ([]int, error) {
+	hdr = strings.Replace(hdr, " ", "", -1)
+	hdr = strings.Replace(hdr, "\"", "'", -1)
+	if !strings.Contains(hdr, "'descr':'<f4'") {
+		return nil, fmt.Errorf("npy.Read: only little-endian float32 ('<f4') data is supported, header: %s", hdr)
+	}
+	if !strings.Contains(hdr, "'fortran_order':False") {
+		return nil, fmt.Errorf("npy.Read: fortran_order data is not supported, header: %s", hdr)
+	}
+	si := strings.Index(hdr, "'shape':(")
+	if si < 0 {
+		return nil, fmt.Errorf("npy.Read: shape not found in header: %s", hdr)
+	}
+	shs := hdr[si+len("'shape':("):]
+	ei := strings.Index(shs, ")")
+	if ei < 0 {
+		return nil, fmt.Errorf("npy.Read: invalid shape in header: %s", hdr)
+	}
+	var shape []int
+	for _, ds := range strings.Split(shs[:ei], ",") {
+		if ds == "" {
+			continue
+		}
+		d, err := strconv.Atoi(ds)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("npy.Read: invalid shape in header: %s", hdr)
+		}
+		shape = append(shape, d)
+	}
+	return shape, nil
+}
+
+// WriteTensor writes given tensor to given writer in .npy format
+func WriteTensor(w io.Writer, tsr *etensor.Float32) error {
+	return Write(w, tsr.Shapes(), tsr.Values)
+}
+
+// ReadTensor reads a tensor in .npy format from the reader
+func ReadTensor(r io.Reader) (*etensor.Float32, error) {
+	shape, vals, err := Read(r)
+	if err != nil {
+		return nil, err
+	}
+	return etensor.NewFloat32Shape(etensor.NewShape(shape, nil, nil), vals), nil
+}
+
+// SaveTensor saves given tensor to given file in .npy format
+func SaveTensor(tsr *etensor.Float32, fname string) error {
+	fp, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	if err := WriteTensor(fp, tsr); err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+// OpenTensor opens a tensor from given file in .npy format
+func OpenTensor(fname string) (*etensor.Float32, error) {
+	fp, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	return ReadTensor(fp)
+}
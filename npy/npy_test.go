@@ -0,0 +1,61 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package npy
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+func TestNpy(t *testing.T) {
+	tsr := etensor.NewFloat32([]int{2, 3}, nil, nil)
+	copy(tsr.Values, []float32{0, -1.5, 3.25, float32(math.Pi), 1e-20, float32(math.Inf(1))})
+	var b bytes.Buffer
+	if err := WriteTensor(&b, tsr); err != nil {
+		t.Fatal(err)
+	}
+	hdr := "\x93NUMPY\x01\x00\x76\x00{'descr': '<f4', 'fortran_order': False, 'shape': (2, 3), }"
+	if !bytes.HasPrefix(b.Bytes(), []byte(hdr)) || b.Len() != 128+6*4 || b.Bytes()[127] != '\n' {
+		t.Errorf("header: %q", b.Bytes()[:128])
+	}
+	fn := filepath.Join(t.TempDir(), "test.npy")
+	if err := SaveTensor(tsr, fn); err != nil {
+		t.Fatal(err)
+	}
+	rt, err := OpenTensor(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rt.Shapes(), []int{2, 3}) || !reflect.DeepEqual(rt.Values, tsr.Values) {
+		t.Errorf("round trip: %v", rt)
+	}
+	// 1D shape uses (n,) tuple syntax
+	b.Reset()
+	Write(&b, []int{4}, []float32{1, 2, 3, 4})
+	shp, vals, err := Read(&b)
+	if err != nil || !reflect.DeepEqual(shp, []int{4}) || vals[3] != 4 {
+		t.Errorf("1D: %v %v %v", shp, vals, err)
+	}
+	if _, _, err := Read(bytes.NewReader([]byte("not npy data"))); err == nil {
+		t.Errorf("expected error for invalid data")
+	}
+	if err := Write(&b, []int{2, 2}, []float32{1}); err == nil {
+		t.Errorf("expected error for shape mismatch")
+	}
+	for _, shp := range []string{"(2, -3)", "(4611686018427387904, 4)", "(65536, 65536)", "(1000000,)"} {
+		hdr := "{'descr': '<f4', 'fortran_order': False, 'shape': " + shp + ", }\n"
+		bad := append([]byte(Magic), 1, 0, byte(len(hdr)), 0)
+		bad = append(bad, hdr...)
+		bad = append(bad, 0, 0, 0, 0) // only 1 value
+		if _, _, err := Read(bytes.NewReader(bad)); err == nil {
+			t.Errorf("shape: %v expected error", shp)
+		}
+	}
+}
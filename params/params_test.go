@@ -6,6 +6,8 @@ package params
 
 import (
 	"bytes"
+	"math"
+	"strconv"
 	"testing"
 
 	"github.com/andreyvit/diff"
@@ -149,3 +151,52 @@ func TestParamSetsWriteGo(t *testing.T) {
 	}
 
 }
+
+func TestSensitivity(t *testing.T) {
+	base := Sheet{
+		{Sel: "Layer", Params: Params{"Layer.Inhib.Layer.Gi": "1.8"}},
+		{Sel: "Prjn", Params: Params{"Prjn.Learn.Lrate": "0.04", "Prjn.WtScale.Rel": "1"}},
+	}
+	getf := func(sh Sheet, sel, path string) float64 {
+		v, _ := strconv.ParseFloat(sh.SelByName(sel).Params[path], 64)
+		return v
+	}
+	sa := &SensitivityAnalysis{BaseParams: base, Magnitude: 0.1, Relative: true, Seed: 1}
+	sa.Params = []SensParam{{"Prjn", "Prjn.Learn.Lrate"}, {"Layer", "Layer.Inhib.Layer.Gi"}, {"Prjn", "Prjn.WtScale.Rel"}}
+	sa.TestFn = func(sh Sheet) float64 { // Gi matters most, Rel not at all
+		return 2*getf(sh, "Layer", "Layer.Inhib.Layer.Gi") + 10*getf(sh, "Prjn", "Prjn.Learn.Lrate")
+	}
+	res, err := sa.RunOAT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res[1].PlusDelta-0.36) > 1e-9 || math.Abs(res[1].MinusDelta+0.36) > 1e-9 || res[2].Effect != 0 {
+		t.Errorf("OAT results: %+v", res)
+	}
+	if base[0].Params["Layer.Inhib.Layer.Gi"] != "1.8" {
+		t.Errorf("base params modified")
+	}
+	SortSensitivityResults(res)
+	if res[0].Param.Path != "Layer.Inhib.Layer.Gi" || res[2].Param.Path != "Prjn.WtScale.Rel" {
+		t.Errorf("sorted OAT results: %+v", res)
+	}
+	// additive model: S1 == ST, proportional to variance of each term:
+	// Gi: (2 * .18)^2 = .1296, Lrate: (10 * .004)^2 = .0016
+	res, err = sa.RunSobol(2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1gi := 0.1296 / (0.1296 + 0.0016)
+	if math.Abs(res[1].S1-s1gi) > 0.05 || math.Abs(res[1].ST-s1gi) > 0.05 || math.Abs(res[2].ST) > 1e-9 || res[0].ST > 0.05 {
+		t.Errorf("Sobol results: %+v", res)
+	}
+	for _, n := range []int{0, -1} {
+		if _, err := sa.RunSobol(n); err == nil {
+			t.Errorf("expected error for RunSobol n: %d", n)
+		}
+	}
+	sa.Params = append(sa.Params, SensParam{"Layer", "Layer.Act.Gain"})
+	if _, err := sa.RunOAT(); err == nil {
+		t.Errorf("expected error for missing param")
+	}
+}
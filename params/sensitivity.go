@@ -0,0 +1,218 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package params
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// SensParam specifies one parameter to vary in a SensitivityAnalysis,
+// which must be present in the BaseParams sheet with a numerical value.
+type SensParam struct {
+	Sel  string `desc:"selector of the Sel in the sheet containing the parameter, e.g., Layer or #Output"`
+	Path string `desc:"path of the parameter within the Params of that Sel, e.g., Layer.Inhib.Layer.Gi"`
+}
+
+// SensitivityResult is the result of a sensitivity analysis for one parameter
+type SensitivityResult struct {
+	Param      SensParam `desc:"the parameter"`
+	Base       float64   `desc:"base value of the parameter"`
+	PlusDelta  float64   `desc:"RunOAT: change in the metric relative to the base metric when the parameter is increased by the perturbation"`
+	MinusDelta float64   `desc:"RunOAT: change in the metric relative to the base metric when the parameter is decreased by the perturbation"`
+	S1         float64   `desc:"RunSobol: first-order Sobol index: proportion of the variance in the metric due to this parameter alone"`
+	ST         float64   `desc:"RunSobol: total-effect Sobol index: proportion of the variance in the metric due to this parameter, including all interactions with other parameters"`
+	Effect     float64   `desc:"overall size of the effect of the parameter, used for sorting: max of abs PlusDelta, MinusDelta for RunOAT, and ST for RunSobol"`
+}
+
+// SensitivityAnalysis determines how sensitive a performance metric is to
+// each of a set of parameters, by running a test function with perturbed
+// values of the parameters.  Each parameter is varied by the Magnitude
+// around its value in the BaseParams sheet.
+type SensitivityAnalysis struct {
+	BaseParams Sheet                      `desc:"base parameters, which must include all the Params to vary -- this is not modified"`
+	TestFn     func(params Sheet) float64 `view:"-" desc:"function that runs the model with given params and returns the performance metric"`
+	Params     []SensParam                `desc:"the parameters to vary"`
+	Magnitude  float64                    `desc:"magnitude of the perturbation of each parameter: a proportion of its base value if Relative, otherwise an absolute amount"`
+	Relative   bool                       `desc:"if true, Magnitude is relative to the base value of each parameter"`
+	Seed       int64                      `desc:"random seed for the sampling in RunSobol"`
+	BaseMetric float64                    `inactive:"+" desc:"value of the metric for the BaseParams, from the last RunOAT"`
+	Results    []SensitivityResult        `desc:"results of the last run"`
+}
+
+// RunOAT runs a one-at-a-time sensitivity analysis: the TestFn is run with
+// the BaseParams, and then with each parameter in turn increased and
+// decreased by the perturbation, with the others at their base values.
+// Requires 2 * len(Params) + 1 runs of the TestFn.  Returns the Results,
+// in the same order as Params (see SortSensitivityResults).
+func (sa *SensitivityAnalysis) RunOAT() ([]SensitivityResult, error) {
+	bases, err := sa.baseVals()
+	if err != nil {
+		return nil, err
+	}
+	sa.BaseMetric = sa.TestFn(sa.BaseParams)
+	sa.Results = make([]SensitivityResult, len(sa.Params))
+	for i, sp := range sa.Params {
+		sr := &sa.Results[i]
+		sr.Param = sp
+		sr.Base = bases[i]
+		vals := append([]float64{}, bases...)
+		vals[i] = bases[i] + sa.delta(bases[i])
+		sr.PlusDelta = sa.TestFn(sa.sheetWith(vals)) - sa.BaseMetric
+		vals[i] = bases[i] - sa.delta(bases[i])
+		sr.MinusDelta = sa.TestFn(sa.sheetWith(vals)) - sa.BaseMetric
+		sr.Effect = math.Max(math.Abs(sr.PlusDelta), math.Abs(sr.MinusDelta))
+	}
+	return sa.Results, nil
+}
+
+// RunSobol runs a variance-based sensitivity analysis, computing the
+// first-order (S1) and total-effect (ST) Sobol indexes for each parameter,
+// using the Saltelli sampling scheme with n base samples, with all parameters
+// varying uniformly within the perturbation range around their base values.
+// Requires n * (len(Params) + 2) runs of the TestFn.  The samples are drawn
+// using a pseudo-random generator seeded with Seed, so n should be large
+// enough (e.g., 1000s) for the estimates to converge.  Returns the Results,
+// in the same order as Params (see SortSensitivityResults).
+// Returns an error if n < 1.
+func (sa *SensitivityAnalysis) RunSobol(n int) ([]SensitivityResult, error) {
+	if n < 1 {
+		err := fmt.Errorf("params.SensitivityAnalysis: RunSobol n: %d must be > 0", n)
+		log.Println(err)
+		return nil, err
+	}
+	bases, err := sa.baseVals()
+	if err != nil {
+		return nil, err
+	}
+	k := len(sa.Params)
+	rnd := rand.New(rand.NewSource(sa.Seed))
+	sample := func() []float64 {
+		vals := make([]float64, k)
+		for i, bv := range bases {
+			d := sa.delta(bv)
+			vals[i] = bv - d + 2*d*rnd.Float64()
+		}
+		return vals
+	}
+	fA := make([]float64, n)
+	fB := make([]float64, n)
+	fAB := make([][]float64, k)
+	for i := range fAB {
+		fAB[i] = make([]float64, n)
+	}
+	for j := 0; j < n; j++ {
+		a := sample()
+		b := sample()
+		fA[j] = sa.TestFn(sa.sheetWith(a))
+		fB[j] = sa.TestFn(sa.sheetWith(b))
+		for i := 0; i < k; i++ {
+			ab := append([]float64{}, a...)
+			ab[i] = b[i]
+			fAB[i][j] = sa.TestFn(sa.sheetWith(ab))
+		}
+	}
+	mn, vr := meanVar(append(append([]float64{}, fA...), fB...))
+	sa.Results = make([]SensitivityResult, k)
+	for i, sp := range sa.Params {
+		sr := &sa.Results[i]
+		sr.Param = sp
+		sr.Base = bases[i]
+		if vr == 0 {
+			continue
+		}
+		var s1, st float64
+		for j := 0; j < n; j++ {
+			s1 += (fB[j] - mn) * (fAB[i][j] - fA[j]) // centering reduces the error of the estimate
+			d := fA[j] - fAB[i][j]
+			st += d * d
+		}
+		sr.S1 = s1 / float64(n) / vr
+		sr.ST = 0.5 * st / float64(n) / vr
+		sr.Effect = sr.ST
+	}
+	return sa.Results, nil
+}
+
+// SortSensitivityResults sorts the results in descending order of
+// the absolute size of their Effect.
+func SortSensitivityResults(res []SensitivityResult) {
+	sort.SliceStable(res, func(i, j int) bool {
+		return math.Abs(res[i].Effect) > math.Abs(res[j].Effect)
+	})
+}
+
+// delta returns the perturbation for given base value
+func (sa *SensitivityAnalysis) delta(base float64) float64 {
+	if sa.Relative {
+		return math.Abs(base) * sa.Magnitude
+	}
+	return sa.Magnitude
+}
+
+// baseVals returns the base values of the Params from the BaseParams sheet
+func (sa *SensitivityAnalysis) baseVals() ([]float64, error) {
+	if sa.TestFn == nil {
+		err := fmt.Errorf("params.SensitivityAnalysis: TestFn is not set")
+		log.Println(err)
+		return nil, err
+	}
+	bases := make([]float64, len(sa.Params))
+	for i, sp := range sa.Params {
+		sl, err := sa.BaseParams.SelByNameTry(sp.Sel)
+		if err != nil {
+			return nil, err
+		}
+		vs, err := sl.Params.ParamByNameTry(sp.Path)
+		if err != nil {
+			return nil, err
+		}
+		bases[i], err = strconv.ParseFloat(vs, 64)
+		if err != nil {
+			err = fmt.Errorf("params.SensitivityAnalysis: param: %v in Sel: %v value: %v is not a number", sp.Path, sp.Sel, vs)
+			log.Println(err)
+			return nil, err
+		}
+	}
+	return bases, nil
+}
+
+// sheetWith returns a copy of the BaseParams sheet with the Params set to given values
+func (sa *SensitivityAnalysis) sheetWith(vals []float64) Sheet {
+	sh := make(Sheet, len(sa.BaseParams))
+	for i, sl := range sa.BaseParams {
+		nsl := *sl
+		nsl.Params = make(Params, len(sl.Params))
+		for k, v := range sl.Params {
+			nsl.Params[k] = v
+		}
+		sh[i] = &nsl
+	}
+	for i, sp := range sa.Params {
+		sh.SelByName(sp.Sel).Params[sp.Path] = strconv.FormatFloat(vals[i], 'g', -1, 64)
+	}
+	return sh
+}
+
+// meanVar returns the mean and (population) variance of the values
+func meanVar(vals []float64) (mn, vr float64) {
+	n := float64(len(vals))
+	if n == 0 {
+		return
+	}
+	for _, v := range vals {
+		mn += v
+	}
+	mn /= n
+	for _, v := range vals {
+		vr += (v - mn) * (v - mn)
+	}
+	vr /= n
+	return
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package patgen
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/emer/etable/etensor"
+	"github.com/goki/ki/ints"
+)
+
+// AugFunc is a function that generates a transformed (augmented) version of the
+// src pattern into the dst pattern (same shape), using given random number generator.
+type AugFunc func(src, dst *etensor.Float32, rng *rand.Rand)
+
+// VocabAugment adds a new pool to the vocabulary named dstName with n rows,
+// each of which is generated by calling augFn on a randomly chosen row from the
+// existing srcName pool.  The new pool has the same shape as the source
+// apart from the number of rows.  See AugFlipBitsNoise and AugShiftPool
+// for standard augmentation functions.  Uses given rng for choosing the rows
+// and in augFn, or a new one seeded from the standard math/rand source if nil.
+// Returns an error if n < 0.
+func VocabAugment(mp Vocab, srcName, dstName string, n int, augFn AugFunc, rng *rand.Rand) (*etensor.Float32, error) {
+	if n < 0 {
+		err := fmt.Errorf("VocabAugment: n: %d must be >= 0", n)
+		log.Println(err)
+		return nil, err
+	}
+	src, err := mp.ByNameTry(srcName)
+	if err != nil {
+		return nil, err
+	}
+	srows := src.Dim(0)
+	if srows == 0 {
+		err := fmt.Errorf("VocabAugment: source pool: %s has no rows", srcName)
+		log.Println(err)
+		return nil, err
+	}
+	shp := append([]int{n}, src.Shapes()[1:]...)
+	tsr := etensor.NewFloat32(shp, nil, src.DimNames())
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	for i := 0; i < n; i++ {
+		srow := src.SubSpace([]int{rng.Intn(srows)}).(*etensor.Float32)
+		trow := tsr.SubSpace([]int{i}).(*etensor.Float32)
+		augFn(srow, trow, rng)
+	}
+	mp[dstName] = tsr
+	return tsr, nil
+}
+
+// AugFlipBitsNoise returns an AugFunc that copies the source pattern and
+// then turns off pct proportion of its active (non-zero) bits (at least 1),
+// and turns on the same number of currently inactive bits, chosen at random,
+// so the number of active bits is preserved.
+func AugFlipBitsNoise(pct float32) AugFunc {
+	return func(src, dst *etensor.Float32, rng *rand.Rand) {
+		copy(dst.Values, src.Values)
+		var ons, offs []int
+		for i, v := range dst.Values {
+			if v == 0 {
+				offs = append(offs, i)
+			} else {
+				ons = append(ons, i)
+			}
+		}
+		nFlip := ints.MinInt(ints.MaxInt(1, NFmPct(pct, len(ons))), ints.MinInt(len(ons), len(offs)))
+		rng.Shuffle(len(ons), func(i, j int) { ons[i], ons[j] = ons[j], ons[i] })
+		rng.Shuffle(len(offs), func(i, j int) { offs[i], offs[j] = offs[j], offs[i] })
+		for i := 0; i < nFlip; i++ {
+			dst.Values[offs[i]] = dst.Values[ons[i]]
+			dst.Values[ons[i]] = 0
+		}
+	}
+}
+
+// AugShiftPool returns an AugFunc that cyclically shifts (with wrap-around)
+// the source pattern by dY, dX in its inner-most Y, X dimensions, which for
+// a 4D pattern shifts the pattern within each pool.
+func AugShiftPool(dY, dX int) AugFunc {
+	return func(src, dst *etensor.Float32, rng *rand.Rand) {
+		ShiftPool(src, dst, dY, dX, true)
+	}
+}
+
+// ShiftPool shifts the src pattern by dY, dX in its inner-most Y, X
+// dimensions into dst, which must have the same shape.  For a 4D pattern,
+// this shifts the pattern within each pool.  If wrap is true, values
+// shifted past an edge wrap around to the other side, otherwise they are
+// lost and the vacated positions are set to 0.
+func ShiftPool(src, dst *etensor.Float32, dY, dX int, wrap bool) {
+	nd := src.NumDims()
+	if nd < 2 {
+		copy(dst.Values, src.Values)
+		return
+	}
+	if !wrap {
+		for i := range dst.Values {
+			dst.Values[i] = 0
+		}
+	}
+	ny := src.Dim(nd - 2)
+	nx := src.Dim(nd - 1)
+	npl := ny * nx
+	nouter := src.Len() / npl
+	for o := 0; o < nouter; o++ {
+		st := o * npl
+		for y := 0; y < ny; y++ {
+			ty := y + dY
+			if wrap {
+				ty = (ty%ny + ny) % ny
+			} else if ty < 0 || ty >= ny {
+				continue
+			}
+			for x := 0; x < nx; x++ {
+				tx := x + dX
+				if wrap {
+					tx = (tx%nx + nx) % nx
+				} else if tx < 0 || tx >= nx {
+					continue
+				}
+				dst.Values[st+ty*nx+tx] = src.Values[st+y*nx+x]
+			}
+		}
+	}
+}
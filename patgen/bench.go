@@ -0,0 +1,165 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package patgen
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// CueFracResult is the result of a CompletionBench for one cue fraction
+type CueFracResult struct {
+	CueFrac   float32   `desc:"proportion of the active bits of each pattern presented in the cue"`
+	Acc       float32   `desc:"mean CompletionAccuracy across all patterns and trials"`
+	NRecalled int       `desc:"number of patterns whose mean accuracy is at or above the RecallCrit"`
+	Missed    float32   `desc:"mean number of active target bits that were not active in the output, per trial"`
+	Spurious  float32   `desc:"mean number of inactive target bits that were active in the output, per trial"`
+	PatAcc    []float32 `desc:"mean accuracy for each pattern (row) in the vocabulary"`
+}
+
+// BenchmarkResult is the result of running a CompletionBench
+type BenchmarkResult struct {
+	NPats    int             `desc:"number of patterns in the vocabulary tested"`
+	CueFracs []CueFracResult `desc:"results for each cue fraction tested, in order"`
+	Capacity int             `desc:"estimated capacity: the maximum NRecalled across the cue fractions tested, i.e., the number of patterns supported at the RecallCrit level of recall"`
+	Stopped  bool            `desc:"true if the benchmark stopped early because the accuracy fell below the StopAcc"`
+}
+
+// Table returns a table of the results, with one row per cue fraction
+func (br *BenchmarkResult) Table() *etable.Table {
+	dt := etable.New(etable.Schema{
+		{Name: "CueFrac", Type: etensor.FLOAT64},
+		{Name: "Acc", Type: etensor.FLOAT64},
+		{Name: "NRecalled", Type: etensor.INT64},
+		{Name: "Missed", Type: etensor.FLOAT64},
+		{Name: "Spurious", Type: etensor.FLOAT64},
+	}, len(br.CueFracs))
+	dt.SetMetaData("name", "CompletionBench")
+	for i, cr := range br.CueFracs {
+		dt.SetCellFloat("CueFrac", i, float64(cr.CueFrac))
+		dt.SetCellFloat("Acc", i, float64(cr.Acc))
+		dt.SetCellFloat("NRecalled", i, float64(cr.NRecalled))
+		dt.SetCellFloat("Missed", i, float64(cr.Missed))
+		dt.SetCellFloat("Spurious", i, float64(cr.Spurious))
+	}
+	return dt
+}
+
+// CompletionBench evaluates the pattern completion (associative memory)
+// performance of a model on the patterns in a vocabulary pool: for each
+// cue fraction, each pattern is presented as a PartialCue, and the output
+// produced by the Trial function is compared with the full pattern.
+// Running the model is algorithm-specific, so it is done by the Trial
+// function, which typically applies the cue to the input layer, runs a
+// testing trial, and returns the output layer values, e.g., using
+// emer.Layer UnitValsTensor.
+type CompletionBench struct {
+	CueFracs   []float32                                                     `desc:"proportions of the active bits of each pattern to present in the cue, tested in order -- typically decreasing"`
+	NTrials    int                                                           `def:"1" desc:"number of trials for each pattern, for each cue fraction, each with a different random cue"`
+	Thresh     float32                                                       `def:"0.5" desc:"threshold above which an output value is counted as active"`
+	RecallCrit float32                                                       `def:"0.9" desc:"accuracy at or above which a pattern is counted as recalled, for NRecalled and Capacity"`
+	StopAcc    float32                                                       `desc:"stop after a cue fraction whose mean accuracy is below this level, skipping the rest -- 0 = never stop early"`
+	Verbose    bool                                                          `desc:"log the results for each cue fraction as it is completed"`
+	Seed       int64                                                         `desc:"random seed for generating the cues"`
+	Trial      func(pat int, cue *etensor.Float32) (*etensor.Float32, error) `view:"-" desc:"function that runs the model with given cue for given pattern (row) and returns the output, which must be the same size as the pattern"`
+}
+
+func (cb *CompletionBench) Defaults() {
+	cb.NTrials = 1
+	cb.Thresh = 0.5
+	cb.RecallCrit = 0.9
+}
+
+// Run runs the benchmark on the patterns in the vocabulary item of given
+// name.  Returns an error if there is no Trial function or NTrials < 1.
+// Any error returned by the Trial function stops the run, and is
+// returned along with the results for the cue fractions completed so far.
+func (cb *CompletionBench) Run(mp Vocab, vocabName string) (*BenchmarkResult, error) {
+	if cb.Trial == nil {
+		err := fmt.Errorf("CompletionBench: Trial function is nil")
+		log.Println(err)
+		return nil, err
+	}
+	if cb.NTrials < 1 {
+		err := fmt.Errorf("CompletionBench: NTrials: %d must be > 0 -- call Defaults to initialize", cb.NTrials)
+		log.Println(err)
+		return nil, err
+	}
+	vtsr, err := mp.ByNameTry(vocabName)
+	if err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewSource(cb.Seed))
+	npats := vtsr.Dim(0)
+	br := &BenchmarkResult{NPats: npats}
+	for _, cf := range cb.CueFracs {
+		cr := CueFracResult{CueFrac: cf, PatAcc: make([]float32, npats)}
+		for pi := 0; pi < npats; pi++ {
+			pat := vtsr.SubSpace([]int{pi}).(*etensor.Float32)
+			for tr := 0; tr < cb.NTrials; tr++ {
+				out, err := cb.Trial(pi, PartialCue(pat, 1-cf, rng))
+				switch {
+				case err != nil:
+				case out == nil:
+					err = fmt.Errorf("CompletionBench: Trial returned no output for pattern: %d cue frac: %g", pi, cf)
+					log.Println(err)
+				case out.Len() != pat.Len():
+					err = fmt.Errorf("CompletionBench: output size: %d != pattern size: %d", out.Len(), pat.Len())
+					log.Println(err)
+				}
+				if err != nil {
+					return br, err
+				}
+				cr.PatAcc[pi] += CompletionAccuracy(out, pat, cb.Thresh)
+				miss, spur := cb.bitErrors(out, pat)
+				cr.Missed += float32(miss)
+				cr.Spurious += float32(spur)
+			}
+		}
+		ntot := float32(npats * cb.NTrials)
+		for pi := range cr.PatAcc {
+			cr.PatAcc[pi] /= float32(cb.NTrials)
+			cr.Acc += cr.PatAcc[pi]
+			if cr.PatAcc[pi] >= cb.RecallCrit {
+				cr.NRecalled++
+			}
+		}
+		cr.Acc /= float32(npats)
+		cr.Missed /= ntot
+		cr.Spurious /= ntot
+		br.CueFracs = append(br.CueFracs, cr)
+		if cr.NRecalled > br.Capacity {
+			br.Capacity = cr.NRecalled
+		}
+		if cb.Verbose {
+			log.Printf("CompletionBench: %s cue frac: %g accuracy: %g recalled: %d / %d missed: %g spurious: %g\n", vocabName, cf, cr.Acc, cr.NRecalled, npats, cr.Missed, cr.Spurious)
+		}
+		if cr.Acc < cb.StopAcc {
+			br.Stopped = true
+			break
+		}
+	}
+	return br, nil
+}
+
+// bitErrors returns the number of active (> .5) target bits not active
+// in the output (> Thresh), and the number of inactive target bits that
+// are active in the output.
+func (cb *CompletionBench) bitErrors(out, targ *etensor.Float32) (missed, spurious int) {
+	for i, tv := range targ.Values {
+		on := out.Values[i] > cb.Thresh
+		switch {
+		case tv > .5 && !on:
+			missed++
+		case tv <= .5 && on:
+			spurious++
+		}
+	}
+	return
+}
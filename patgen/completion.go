@@ -0,0 +1,52 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package patgen
+
+import (
+	"log"
+	"math/rand"
+
+	"github.com/emer/etable/etensor"
+)
+
+// PartialCue returns a copy of the given pattern with a random maskFrac
+// proportion of its active (> .5) values set to 0, for use as a partial
+// cue in testing pattern completion.  Uses given rng, or a new one seeded
+// from the standard math/rand source if nil.
+func PartialCue(pat *etensor.Float32, maskFrac float32, rng *rand.Rand) *etensor.Float32 {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	cue := pat.Clone().(*etensor.Float32)
+	on := onIdxs(cue.Values)
+	nMask := NFmPct(maskFrac, len(on))
+	for _, pi := range rng.Perm(len(on))[:nMask] {
+		cue.Values[on[pi]] = 0
+	}
+	return cue
+}
+
+// CompletionAccuracy returns the proportion of the active (> .5) values in
+// the target pattern that are recovered in the output pattern, i.e., have
+// an output value > thresh, as a measure of pattern completion from a
+// PartialCue.  Returns 0 if the patterns differ in size or the target
+// has no active values.
+func CompletionAccuracy(output, target *etensor.Float32, thresh float32) float32 {
+	if output.Len() != target.Len() {
+		log.Printf("CompletionAccuracy: output size: %d != target size: %d\n", output.Len(), target.Len())
+		return 0
+	}
+	on := onIdxs(target.Values)
+	if len(on) == 0 {
+		return 0
+	}
+	nrec := 0
+	for _, i := range on {
+		if output.Values[i] > thresh {
+			nrec++
+		}
+	}
+	return float32(nrec) / float32(len(on))
+}
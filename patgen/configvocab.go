@@ -10,7 +10,9 @@ import (
 	"math"
 	"math/rand"
 
+	"github.com/emer/etable/etable"
 	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
 	"github.com/emer/etable/tsragg"
 	"github.com/goki/ki/ints"
 )
@@ -56,11 +58,70 @@ func NFmPct(pct float32, n int) int {
 // AddVocabEmpty adds an empty pool to the vocabulary.
 // This can be used to make test cases with missing pools.
 func AddVocabEmpty(mp Vocab, name string, rows, poolY, poolX int) (*etensor.Float32, error) {
-	tsr := etensor.NewFloat32([]int{rows, poolY, poolX}, nil, []string{"row", "Y", "X"})
+	return AddVocabConst(mp, name, []int{rows, poolY, poolX}, nil, 0)
+}
+
+// AddVocabConst adds a pool of given shape to the vocabulary, with all
+// values set to val, e.g., for a baseline to subtract from other patterns.
+// The first dimension of shape is the number of rows (patterns), and the
+// rest is the shape of each pattern, which can have any number of dimensions,
+// e.g., [rows, poolsY, poolsX, Y, X] for patterns for a 4D layer.
+// If dimNames is nil, defaults are used: "row" for the first dimension,
+// and Y, X for the last two, with Pool or PoolY, PoolX for the outer
+// dimensions of 4D or 5D shapes, and D<n> otherwise.
+func AddVocabConst(mp Vocab, name string, shape []int, dimNames []string, val float32) (*etensor.Float32, error) {
+	if len(shape) == 0 {
+		err := fmt.Errorf("AddVocabConst: shape for: %s is empty", name)
+		log.Println(err)
+		return nil, err
+	}
+	for _, sz := range shape {
+		if sz <= 0 {
+			err := fmt.Errorf("AddVocabConst: shape for: %s must have all positive sizes, not: %v", name, shape)
+			log.Println(err)
+			return nil, err
+		}
+	}
+	if dimNames == nil {
+		dimNames = vocabDimNames(len(shape))
+	} else if len(dimNames) != len(shape) {
+		err := fmt.Errorf("AddVocabConst: number of dimNames: %d for: %s does not match number of dimensions: %d", len(dimNames), name, len(shape))
+		log.Println(err)
+		return nil, err
+	}
+	shp := append([]int{}, shape...)
+	nms := append([]string{}, dimNames...)
+	tsr := etensor.NewFloat32(shp, nil, nms)
+	if val != 0 {
+		for i := range tsr.Values {
+			tsr.Values[i] = val
+		}
+	}
 	mp[name] = tsr
 	return tsr, nil
 }
 
+// vocabDimNames returns the default dimension names for a vocabulary
+// tensor with given number of dimensions, including the row
+func vocabDimNames(nd int) []string {
+	switch nd {
+	case 2:
+		return []string{"row", "X"}
+	case 3:
+		return []string{"row", "Y", "X"}
+	case 4:
+		return []string{"row", "Pool", "Y", "X"}
+	case 5:
+		return []string{"row", "PoolY", "PoolX", "Y", "X"}
+	}
+	nms := make([]string, nd)
+	nms[0] = "row"
+	for i := 1; i < nd; i++ {
+		nms[i] = fmt.Sprintf("D%d", i)
+	}
+	return nms
+}
+
 // AddVocabPermutedBinary adds a permuted binary pool to the vocabulary.
 // This is a good source of random patterns with no systematic similarity.
 // pctAct = proportion (0-1) bits turned on for a pool.
@@ -79,6 +140,29 @@ func AddVocabPermutedBinary(mp Vocab, name string, rows, poolY, poolX int, pctAc
 	return tsr, err
 }
 
+// AddVocabDistinct adds a permuted binary pool to the vocabulary, where every
+// row is guaranteed to be at least minDist bits different (Hamming distance)
+// from every other row, by rejecting and resampling rows that are too close to
+// the previous ones, up to maxTries times per row (e.g., 1000).
+// pctAct = proportion (0-1) bits turned on for a pool.
+// Note that two patterns with the same number of bits on always differ
+// by an even number of bits, up to a maximum of 2 * nOn.
+// Returns an error if maxTries < 1, or if the constraint cannot be satisfied
+// within maxTries for some row.  Uses given rng, or a new one seeded from the
+// standard math/rand source if nil.
+func AddVocabDistinct(mp Vocab, name string, rows, poolY, poolX int, pctAct float32, minDist, maxTries int, rng *rand.Rand) (*etensor.Float32, error) {
+	if maxTries < 1 {
+		err := fmt.Errorf("AddVocabDistinct: maxTries: %d must be > 0", maxTries)
+		log.Println(err)
+		return nil, err
+	}
+	nOn := NFmPct(pctAct, poolY*poolX)
+	tsr := etensor.NewFloat32([]int{rows, poolY, poolX}, nil, []string{"row", "Y", "X"})
+	err := PermutedBinaryMinDist(tsr, nOn, 1, 0, minDist, maxTries, rng)
+	mp[name] = tsr
+	return tsr, err
+}
+
 // AddVocabClone clones an existing pool in the vocabulary to make a new one.
 func AddVocabClone(mp Vocab, name string, copyFrom string) (*etensor.Float32, error) {
 	cp, err := mp.ByNameTry(copyFrom)
@@ -98,8 +182,7 @@ func AddVocabRepeat(mp Vocab, name string, rows int, copyFrom string, copyRow in
 		return nil, err
 	}
 	tsr := &etensor.Float32{}
-	cpshp := cp.Shapes()
-	cpshp[0] = rows
+	cpshp := append([]int{rows}, cp.Shapes()[1:]...)
 	tsr.SetShape(cpshp, nil, cp.DimNames())
 	mp[name] = tsr
 	cprow := cp.SubSpace([]int{copyRow})
@@ -110,6 +193,31 @@ func AddVocabRepeat(mp Vocab, name string, rows int, copyFrom string, copyRow in
 	return tsr, nil
 }
 
+// AddVocabRepeatRows adds a pool to the vocabulary with one row for each
+// entry in rowSchedule, copied from the indicated row of the copyFrom pool.
+// This can be used to construct structured sequences from a small set of
+// base patterns.  Returns an error if any index is out of range.
+func AddVocabRepeatRows(mp Vocab, name string, copyFrom string, rowSchedule []int) (*etensor.Float32, error) {
+	cp, err := mp.ByNameTry(copyFrom)
+	if err != nil {
+		return nil, err
+	}
+	rows, cells := cp.RowCellSize()
+	for i, ri := range rowSchedule {
+		if ri < 0 || ri >= rows {
+			err := fmt.Errorf("AddVocabRepeatRows: rowSchedule[%d]: %d is out of range for pool: %s with: %d rows", i, ri, copyFrom, rows)
+			log.Println(err)
+			return nil, err
+		}
+	}
+	tsr := etensor.NewFloat32(append([]int{len(rowSchedule)}, cp.Shapes()[1:]...), nil, cp.DimNames())
+	for i, ri := range rowSchedule {
+		copy(tsr.Values[i*cells:(i+1)*cells], cp.Values[ri*cells:(ri+1)*cells])
+	}
+	mp[name] = tsr
+	return tsr, nil
+}
+
 // AddVocabDrift adds a row-by-row drifting pool to the vocabulary,
 // starting from the given row in existing vocabulary item
 // (which becomes starting row in this one -- drift starts in second row).
@@ -121,8 +229,7 @@ func AddVocabDrift(mp Vocab, name string, rows int, pctDrift float32, copyFrom s
 		return nil, err
 	}
 	tsr := &etensor.Float32{}
-	cpshp := cp.Shapes()
-	cpshp[0] = rows
+	cpshp := append([]int{rows}, cp.Shapes()[1:]...)
 	tsr.SetShape(cpshp, nil, cp.DimNames())
 	mp[name] = tsr
 	cprow := cp.SubSpace([]int{copyRow}).(*etensor.Float32)
@@ -140,15 +247,291 @@ func AddVocabDrift(mp Vocab, name string, rows int, pctDrift float32, copyFrom s
 	return tsr, nil
 }
 
+// AddVocabShifts adds a pool to the vocabulary with spatially-shifted copies
+// of the copyRow row of the copyFrom pool, e.g., for training translation
+// invariance.  There is one row for each (shiftsX[i], shiftsY[i]) pair, so
+// the two lists must have the same length.  The pattern is shifted in its
+// inner-most Y, X dimensions (within each pool for 4D patterns), and if wrap
+// is true, values shifted past an edge wrap around, otherwise they are lost
+// (see ShiftPool).
+func AddVocabShifts(mp Vocab, name, copyFrom string, copyRow int, shiftsX, shiftsY []int, wrap bool) (*etensor.Float32, error) {
+	cp, err := mp.ByNameTry(copyFrom)
+	if err != nil {
+		return nil, err
+	}
+	if copyRow < 0 || copyRow >= cp.Dim(0) {
+		err := fmt.Errorf("AddVocabShifts: copyRow: %d is out of range for pool: %s with: %d rows", copyRow, copyFrom, cp.Dim(0))
+		log.Println(err)
+		return nil, err
+	}
+	if len(shiftsX) != len(shiftsY) {
+		err := fmt.Errorf("AddVocabShifts: shiftsX: %d and shiftsY: %d must have the same number of shifts", len(shiftsX), len(shiftsY))
+		log.Println(err)
+		return nil, err
+	}
+	tsr := etensor.NewFloat32(append([]int{len(shiftsX)}, cp.Shapes()[1:]...), nil, cp.DimNames())
+	mp[name] = tsr
+	cprow := cp.SubSpace([]int{copyRow}).(*etensor.Float32)
+	for i, dx := range shiftsX {
+		ShiftPool(cprow, tsr.SubSpace([]int{i}).(*etensor.Float32), shiftsY[i], dx, wrap)
+	}
+	return tsr, nil
+}
+
+// AddVocabCorrelated adds a pool to the vocabulary with a controlled level of
+// correlation among the rows.  The first row is a random base pattern with
+// pctAct proportion of bits active, and for each subsequent row, each active bit
+// of the base pattern is independently kept with probability targetCorr,
+// or otherwise redrawn at a random location that is inactive in the base
+// pattern, so each row has the same number of active bits as the base, and
+// shares on average a targetCorr proportion of them with the base (unless
+// pctAct > .5, where there may not be enough inactive locations, and some
+// dropped bits are then restored).  Thus, targetCorr = 1 produces all
+// identical rows, and 0 produces rows that share no bits with the base.
+// The mean pairwise correlation achieved among all rows is logged, for comparison
+// with the target.  rng is the random number generator to use -- if nil, a new one
+// is seeded from the standard math/rand source.
+// Returns an error if targetCorr is outside of the [0,1] range.
+func AddVocabCorrelated(mp Vocab, name string, rows, poolY, poolX int, pctAct, targetCorr float32, rng *rand.Rand) (*etensor.Float32, error) {
+	if targetCorr < 0 || targetCorr > 1 {
+		err := fmt.Errorf("AddVocabCorrelated: targetCorr: %g must be between 0 and 1", targetCorr)
+		log.Println(err)
+		return nil, err
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	cells := poolY * poolX
+	nOn := NFmPct(pctAct, cells)
+	tsr := etensor.NewFloat32([]int{rows, poolY, poolX}, nil, []string{"row", "Y", "X"})
+	mp[name] = tsr
+	if rows == 0 || cells == 0 {
+		return tsr, nil
+	}
+	perm := rng.Perm(cells)
+	base := perm[:nOn]
+	off := perm[nOn:] // inactive in base
+	for _, ci := range base {
+		tsr.Values[ci] = 1
+	}
+	for rw := 1; rw < rows; rw++ {
+		row := tsr.Values[rw*cells : (rw+1)*cells]
+		var drop []int
+		for _, ci := range base {
+			if rng.Float32() < targetCorr {
+				row[ci] = 1
+			} else {
+				drop = append(drop, ci)
+			}
+		}
+		nOff := len(drop)
+		if nOff > len(off) {
+			nOff = len(off)
+		}
+		for _, oi := range rng.Perm(len(off))[:nOff] {
+			row[off[oi]] = 1
+		}
+		for _, ci := range drop[nOff:] { // not enough inactive locations
+			row[ci] = 1
+		}
+	}
+	if rows > 1 {
+		log.Printf("AddVocabCorrelated: %s: mean pairwise correlation: %g target: %g\n", name, MeanRowCorrel(tsr), targetCorr)
+	}
+	return tsr, nil
+}
+
+// AddVocabSequential adds a pool to the vocabulary with temporal sequence
+// structure, where each group of seqLen consecutive rows forms one sequence
+// (rows / seqLen sequences, with the last one shorter if not even).
+// All rows share a withinSeqSim proportion of their active bits with the
+// other rows in the same sequence, and a betweenSeqSim proportion with the
+// rows in other sequences, with the remaining bits chosen at random for
+// each row.  As the random bits can overlap by chance, the actual cosine
+// similarities are somewhat higher than these values, especially for
+// higher pctAct.  Uses given rng, or a new one seeded from the standard
+// math/rand source if nil.  Returns an error if withinSeqSim < betweenSeqSim.
+func AddVocabSequential(mp Vocab, name string, rows, poolY, poolX int, pctAct, withinSeqSim, betweenSeqSim float32, seqLen int, rng *rand.Rand) (*etensor.Float32, error) {
+	if withinSeqSim < betweenSeqSim || betweenSeqSim < 0 || withinSeqSim > 1 || seqLen < 1 {
+		err := fmt.Errorf("AddVocabSequential: must have 0 <= betweenSeqSim: %g <= withinSeqSim: %g <= 1, and seqLen: %d >= 1", betweenSeqSim, withinSeqSim, seqLen)
+		log.Println(err)
+		return nil, err
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	cells := poolY * poolX
+	nOn := NFmPct(pctAct, cells)
+	nBetw := NFmPct(betweenSeqSim, nOn)
+	nWith := NFmPct(withinSeqSim, nOn)
+	tsr := etensor.NewFloat32([]int{rows, poolY, poolX}, nil, []string{"row", "Y", "X"})
+	mp[name] = tsr
+	if rows == 0 || cells == 0 {
+		return tsr, nil
+	}
+	perm := rng.Perm(cells)
+	glob := perm[:nBetw]  // shared by all rows
+	other := perm[nBetw:] // available for sequence and row-specific bits
+	var seq []int
+	for rw := 0; rw < rows; rw++ {
+		if rw%seqLen == 0 {
+			seq = seq[:0]
+			for _, oi := range rng.Perm(len(other))[:nWith-nBetw] {
+				seq = append(seq, other[oi])
+			}
+		}
+		row := tsr.Values[rw*cells : (rw+1)*cells]
+		for _, ci := range glob {
+			row[ci] = 1
+		}
+		for _, ci := range seq {
+			row[ci] = 1
+		}
+		for nRnd := nOn - nWith; nRnd > 0; {
+			ci := other[rng.Intn(len(other))]
+			if row[ci] == 0 {
+				row[ci] = 1
+				nRnd--
+			}
+		}
+	}
+	return tsr, nil
+}
+
+// MeanRowCorrel returns the mean correlation between all pairs of rows in
+// the given tensor (outer-most dimension is row, as in columns of etable.Table).
+func MeanRowCorrel(tsr *etensor.Float32) float32 {
+	rows, cells := tsr.RowCellSize()
+	if rows < 2 {
+		return 0
+	}
+	sum := float32(0)
+	n := 0
+	for i := 0; i < rows; i++ {
+		for j := 0; j < i; j++ {
+			sum += metric.Correlation32(tsr.Values[i*cells:(i+1)*cells], tsr.Values[j*cells:(j+1)*cells])
+			n++
+		}
+	}
+	return sum / float32(n)
+}
+
+// AddVocabFromTable adds a pool to the vocabulary with a copy of the patterns in
+// given column of given table, e.g., patterns generated externally and loaded from
+// a CSV file.  The column must have a tensor cell, and a 2D cell becomes a
+// standard [row, Y, X] pool.  Scalar columns return an error.
+func AddVocabFromTable(mp Vocab, name string, dt *etable.Table, colName string) (*etensor.Float32, error) {
+	col, err := dt.ColByNameTry(colName)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if col.NumDims() < 2 {
+		err := fmt.Errorf("AddVocabFromTable: column: %s in table: %s is a scalar column -- must have tensor cells", colName, dt.MetaData["name"])
+		log.Println(err)
+		return nil, err
+	}
+	shp := append([]int{dt.Rows}, col.Shapes()[1:]...)
+	nms := col.DimNames()
+	if len(shp) == 3 {
+		nms = []string{"row", "Y", "X"}
+	}
+	tsr := etensor.NewFloat32(shp, nil, nms)
+	for i := range tsr.Values {
+		tsr.Values[i] = float32(col.FloatVal1D(i))
+	}
+	mp[name] = tsr
+	return tsr, nil
+}
+
+// AddVocabMix adds a pool to the vocabulary where each row is a mixture of
+// the active bits in the corresponding rows of poolA and poolB, which must
+// have the same shape.  A random pctA proportion of the active bits in the
+// poolA row are used, together with a random (1-pctA) proportion of the
+// active bits in the poolB row.  Bits that are active in both rows only
+// count once, so if there are not enough distinct bits in the poolB row,
+// additional bits from the poolA row are used, such that the number of
+// active bits stays at the weighted sum of the two (as much as possible).
+// This can be used to parametrically vary the similarity between categories.
+// Uses given rng to choose the bits, or a new one seeded from the standard
+// math/rand source if nil.
+func AddVocabMix(mp Vocab, name string, poolA, poolB string, pctA float32, rng *rand.Rand) (*etensor.Float32, error) {
+	if pctA < 0 || pctA > 1 {
+		err := fmt.Errorf("AddVocabMix: pctA: %g must be between 0 and 1", pctA)
+		log.Println(err)
+		return nil, err
+	}
+	ta, err := mp.ByNameTry(poolA)
+	if err != nil {
+		return nil, err
+	}
+	tb, err := mp.ByNameTry(poolB)
+	if err != nil {
+		return nil, err
+	}
+	if !etensor.EqualInts(ta.Shapes(), tb.Shapes()) {
+		err := fmt.Errorf("AddVocabMix: pools: %s and %s must have the same shape: %v != %v", poolA, poolB, ta.Shapes(), tb.Shapes())
+		log.Println(err)
+		return nil, err
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	tsr := etensor.NewFloat32(append([]int{}, ta.Shapes()...), nil, ta.DimNames())
+	mp[name] = tsr
+	rows, cells := ta.RowCellSize()
+	for rw := 0; rw < rows; rw++ {
+		ra := ta.Values[rw*cells : (rw+1)*cells]
+		rb := tb.Values[rw*cells : (rw+1)*cells]
+		row := tsr.Values[rw*cells : (rw+1)*cells]
+		ona := onIdxs(ra)
+		onb := onIdxs(rb)
+		na := int(math.Round(float64(pctA) * float64(len(ona))))
+		nb := int(math.Round(float64(1-pctA) * float64(len(onb))))
+		rng.Shuffle(len(ona), func(i, j int) { ona[i], ona[j] = ona[j], ona[i] })
+		rng.Shuffle(len(onb), func(i, j int) { onb[i], onb[j] = onb[j], onb[i] })
+		for _, ci := range ona[:na] {
+			row[ci] = 1
+		}
+		for _, ci := range onb {
+			if nb == 0 {
+				break
+			}
+			if row[ci] == 0 {
+				row[ci] = 1
+				nb--
+			}
+		}
+		for _, ci := range ona[na:] { // overlap: fill remainder from A
+			if nb == 0 {
+				break
+			}
+			row[ci] = 1
+			nb--
+		}
+	}
+	return tsr, nil
+}
+
+// onIdxs returns the indexes of the active (> .5) values
+func onIdxs(vals []float32) []int {
+	var idxs []int
+	for i, v := range vals {
+		if v > 0.5 {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
+
 // VocabShuffle shuffles a pool in the vocabulary on its first dimension (row).
+// Works for any number of inner (pool) dimensions.
 func VocabShuffle(mp Vocab, shufflePools []string) {
 	for _, key := range shufflePools {
 		tsr := mp[key]
 		rows := tsr.Shapes()[0]
-		poolY := tsr.Shapes()[1]
-		poolX := tsr.Shapes()[2]
 		sRows := rand.Perm(rows)
-		sTsr := etensor.NewFloat32([]int{rows, poolY, poolX}, nil, []string{"row", "Y", "X"})
+		sTsr := etensor.NewFloat32(append([]int{}, tsr.Shapes()...), nil, tsr.DimNames())
 		for iRow, sRow := range sRows {
 			sTsr.SubSpace([]int{iRow}).CopyFrom(tsr.SubSpace([]int{sRow}))
 		}
@@ -157,20 +540,21 @@ func VocabShuffle(mp Vocab, shufflePools []string) {
 }
 
 // VocabConcat contatenates several pools in the vocabulary and store it into newPool (could be one of the previous pools).
+// The pools must all have the same inner (pool) shape, of any number of dimensions.
 func VocabConcat(mp Vocab, newPool string, frmPools []string) error {
 	tsr := mp[frmPools[0]].Clone().(*etensor.Float32)
 	for i, key := range frmPools {
 		if i > 0 {
 			// check pool shape
-			if !(tsr.SubSpace([]int{0}).(*etensor.Float32).Shape.IsEqual(&mp[key].SubSpace([]int{0}).(*etensor.Float32).Shape)) {
-				err := fmt.Errorf("shapes of input pools must be the same") // how do I stop the program?
+			if !etensor.EqualInts(tsr.Shapes()[1:], mp[key].Shapes()[1:]) {
+				err := fmt.Errorf("shapes of input pools must be the same: %v vs. %v", tsr.Shapes()[1:], mp[key].Shapes()[1:])
 				log.Println(err.Error())
 				return err
 			}
 
 			currows := tsr.Shapes()[0]
 			approws := mp[key].Shapes()[0]
-			tsr.SetShape([]int{currows + approws, tsr.Shapes()[1], tsr.Shapes()[2]}, nil, []string{"row", "Y", "X"})
+			tsr.SetShape(append([]int{currows + approws}, tsr.Shapes()[1:]...), nil, tsr.DimNames())
 			for iRow := 0; iRow < approws; iRow++ {
 				subtsr := tsr.SubSpace([]int{iRow + currows})
 				subtsr.CopyFrom(mp[key].SubSpace([]int{iRow}))
@@ -183,10 +567,12 @@ func VocabConcat(mp Vocab, newPool string, frmPools []string) error {
 
 // VocabSlice slices a pool in the vocabulary into new ones.
 // SliceOffs is the cutoff points in the original pool, should have one more element than newPools.
+// See SliceRows for the slicing of any tensor.
 func VocabSlice(mp Vocab, frmPool string, newPools []string, sliceOffs []int) error {
-	oriTsr := mp[frmPool]
-	poolY := oriTsr.Shapes()[1]
-	poolX := oriTsr.Shapes()[2]
+	oriTsr, err := mp.ByNameTry(frmPool)
+	if err != nil {
+		return err
+	}
 
 	// check newPools and sliceOffs have same length
 	if len(newPools)+1 != len(sliceOffs) {
@@ -195,31 +581,51 @@ func VocabSlice(mp Vocab, frmPool string, newPools []string, sliceOffs []int) er
 		return err
 	}
 
+	newTsrs, err := SliceRows(oriTsr, sliceOffs)
+	if err != nil {
+		return err
+	}
+	for i, newPool := range newPools {
+		mp[newPool] = newTsrs[i]
+	}
+	return nil
+}
+
+// SliceRows slices the given tensor on its first dimension (row) into new
+// tensors, returning one for each consecutive pair of the cutoff points in
+// sliceOffs, which must increase progressively and be within the number of rows.
+// The values are copied, so the new tensors do not share memory with tsr.
+func SliceRows(tsr *etensor.Float32, sliceOffs []int) ([]*etensor.Float32, error) {
+	if len(sliceOffs) == 0 {
+		err := fmt.Errorf("SliceRows: sliceOffs must have at least one element")
+		log.Println(err.Error())
+		return nil, err
+	}
+	rows, cells := tsr.RowCellSize()
+
 	// check sliceOffs is in right order
-	preVal := sliceOffs[0]
 	for i, curVal := range sliceOffs {
-		if i > 0 {
-			if preVal < curVal {
-				preVal = curVal
-			} else {
-				err := fmt.Errorf("sliceOffs should increase progressively") // how do I stop the program?
-				log.Println(err.Error())
-				return err
-			}
+		if curVal < 0 || curVal > rows {
+			err := fmt.Errorf("SliceRows: sliceOffs[%d]: %d is out of range for: %d rows", i, curVal, rows)
+			log.Println(err.Error())
+			return nil, err
+		}
+		if i > 0 && curVal <= sliceOffs[i-1] {
+			err := fmt.Errorf("sliceOffs should increase progressively")
+			log.Println(err.Error())
+			return nil, err
 		}
 	}
 
 	// slice
-	frmOff := sliceOffs[0]
-	for i := range newPools {
+	poolShp := tsr.Shapes()[1:]
+	newTsrs := make([]*etensor.Float32, len(sliceOffs)-1)
+	for i := range newTsrs {
+		frmOff := sliceOffs[i]
 		toOff := sliceOffs[i+1]
-		newPool := newPools[i]
-		newTsr := etensor.NewFloat32([]int{toOff - frmOff, poolY, poolX}, nil, []string{"row", "Y", "X"})
-		for off := frmOff; off < toOff; off++ {
-			newTsr.SubSpace([]int{off - frmOff}).CopyFrom(oriTsr.SubSpace([]int{off}))
-		}
-		mp[newPool] = newTsr
-		frmOff = toOff
+		newTsr := etensor.NewFloat32(append([]int{toOff - frmOff}, poolShp...), nil, tsr.DimNames())
+		copy(newTsr.Values, tsr.Values[frmOff*cells:toOff*cells])
+		newTsrs[i] = newTsr
 	}
-	return nil
+	return newTsrs, nil
 }
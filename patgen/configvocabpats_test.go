@@ -2,11 +2,16 @@ package patgen
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/emer/emergent/env"
 	"github.com/emer/etable/etable"
 	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
 )
 
 func TestVocab(t *testing.T) {
@@ -65,3 +70,711 @@ func TestVocab(t *testing.T) {
 	fmt.Println(dt.ColByName("ECout").Shapes())
 	fmt.Println(dt.ColByName("ECout").T())
 }
+
+func TestVocabDistinct(t *testing.T) {
+	m := make(Vocab)
+	tsr, err := AddVocabDistinct(m, "D", 10, 5, 5, 0.2, 6, 1000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rw := 1; rw < 10; rw++ {
+		min, _ := RowVsPrevDist32(tsr, rw, metric.Hamming32)
+		if min < 6 {
+			t.Errorf("row: %d min distance: %v < 6", rw, min)
+		}
+	}
+	// 2 * nOn is the max possible distance, so more is impossible
+	if _, err := AddVocabDistinct(m, "X", 4, 5, 5, 0.2, 11, 50, nil); err == nil {
+		t.Errorf("expected error for impossible minDist")
+	}
+	// 6 rows of 5 bits can only be 10 apart if disjoint, which 25 cells can't fit
+	if _, err := AddVocabDistinct(m, "X", 6, 5, 5, 0.2, 10, 1000, nil); err == nil {
+		t.Errorf("expected error when maxTries is exhausted")
+	}
+	if _, err := AddVocabDistinct(m, "X", 4, 5, 5, 0.2, 2, 0, nil); err == nil {
+		t.Errorf("expected error for maxTries 0")
+	}
+	a, _ := AddVocabDistinct(m, "A", 10, 5, 5, 0.2, 6, 1000, rand.New(rand.NewSource(3)))
+	b, _ := AddVocabDistinct(m, "B", 10, 5, 5, 0.2, 6, 1000, rand.New(rand.NewSource(3)))
+	if !reflect.DeepEqual(a.Values, b.Values) {
+		t.Errorf("same rng seed should give the same patterns")
+	}
+}
+
+func TestVocabCorrelated(t *testing.T) {
+	m := make(Vocab)
+	rng := rand.New(rand.NewSource(1))
+	lo, err := AddVocabCorrelated(m, "Lo", 20, 10, 10, 0.2, 0.2, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hi, _ := AddVocabCorrelated(m, "Hi", 20, 10, 10, 0.2, 0.9, rng)
+	for rw := 0; rw < 20; rw++ {
+		if non := NOnInTensor(hi.SubSpace([]int{rw}).(*etensor.Float32)); non != 20 {
+			t.Errorf("row: %d n on: %d != 20", rw, non)
+		}
+	}
+	if MeanRowCorrel(hi) <= MeanRowCorrel(lo) {
+		t.Errorf("high target correlation: %v not > low: %v", MeanRowCorrel(hi), MeanRowCorrel(lo))
+	}
+	// each row shares on average targetCorr of the base pattern bits
+	for _, tc := range []float32{0, 0.2, 0.5, 0.9} {
+		tsr, _ := AddVocabCorrelated(m, "T", 50, 20, 20, 0.1, tc, rng)
+		base := tsr.SubSpace([]int{0}).(*etensor.Float32)
+		sum := float32(0)
+		for rw := 1; rw < 50; rw++ {
+			row := tsr.SubSpace([]int{rw}).(*etensor.Float32)
+			sum += metric.InnerProduct32(row.Values, base.Values) / 40
+		}
+		if ov := sum / 49; math.Abs(float64(ov-tc)) > 0.05 {
+			t.Errorf("target correlation: %g mean overlap with base: %g", tc, ov)
+		}
+	}
+	same, _ := AddVocabCorrelated(m, "Same", 5, 4, 4, 0.25, 1, rng)
+	if MeanRowCorrel(same) < 0.9999 {
+		t.Errorf("target correlation 1 not all same: %v", MeanRowCorrel(same))
+	}
+	if _, err := AddVocabCorrelated(m, "Bad", 5, 4, 4, 0.25, 1.5, rng); err == nil {
+		t.Errorf("expected error for targetCorr > 1")
+	}
+}
+
+func TestVocabAugment(t *testing.T) {
+	m := make(Vocab)
+	AddVocabPermutedBinary(m, "A", 4, 3, 4, 0.25, 0)
+	noisy, err := VocabAugment(m, "A", "AN", 10, AugFlipBitsNoise(0.35), rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(noisy.Shapes(), []int{10, 3, 4}) {
+		t.Errorf("noisy shape: %v", noisy.Shapes())
+	}
+	for rw := 0; rw < 10; rw++ {
+		if non := NOnInTensor(noisy.SubSpace([]int{rw}).(*etensor.Float32)); non != 3 {
+			t.Errorf("noisy row: %d n on: %d != 3", rw, non)
+		}
+	}
+	again, _ := VocabAugment(m, "A", "AN2", 10, AugFlipBitsNoise(0.35), rand.New(rand.NewSource(3)))
+	if !reflect.DeepEqual(again.Values, noisy.Values) {
+		t.Errorf("same seed gave different results")
+	}
+	shift, _ := VocabAugment(m, "A", "AS", 6, AugShiftPool(1, -1), nil)
+	if shift.Dim(0) != 6 || m["AS"] != shift {
+		t.Errorf("shift: %v", shift.Shapes())
+	}
+	src := etensor.NewFloat32([]int{2, 2}, nil, nil)
+	src.Values[0] = 1 // y=0, x=0
+	dst := etensor.NewFloat32([]int{2, 2}, nil, nil)
+	AugShiftPool(1, -1)(src, dst, nil)
+	if dst.Values[3] != 1 { // y=1, x=1
+		t.Errorf("shifted: %v", dst.Values)
+	}
+	if _, err := VocabAugment(m, "A", "X", -1, AugShiftPool(1, 1), nil); err == nil {
+		t.Errorf("expected error for n < 0")
+	}
+	if _, err := VocabAugment(m, "Nope", "X", 2, AugShiftPool(1, 1), nil); err == nil {
+		t.Errorf("expected error for missing source")
+	}
+}
+
+func TestVocab4D(t *testing.T) {
+	m := make(Vocab)
+	a := etensor.NewFloat32([]int{4, 2, 2, 3, 3}, nil, []string{"row", "PY", "PX", "Y", "X"})
+	PermutedBinaryRows(a, 4, 1, 0)
+	m["A"] = a
+	AddVocabClone(m, "B", "A")
+	VocabShuffle(m, []string{"B"})
+	if !reflect.DeepEqual(m["B"].Shapes(), []int{4, 2, 2, 3, 3}) {
+		t.Errorf("shuffle shape: %v", m["B"].Shapes())
+	}
+	if err := VocabConcat(m, "AB", []string{"A", "B"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m["AB"].Shapes(), []int{8, 2, 2, 3, 3}) {
+		t.Errorf("concat shape: %v", m["AB"].Shapes())
+	}
+	AddVocabEmpty(m, "E", 2, 3, 3)
+	if err := VocabConcat(m, "AE", []string{"A", "E"}); err == nil {
+		t.Errorf("expected error for different pool shapes")
+	}
+	if err := VocabSlice(m, "AB", []string{"A'", "B'"}, []int{0, 4, 8}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m["A'"].Values, a.Values) || !reflect.DeepEqual(m["B'"].Shapes(), []int{4, 2, 2, 3, 3}) {
+		t.Errorf("slice: %v", m["B'"].Shapes())
+	}
+	AddVocabRepeat(m, "R", 6, "A", 1)
+	if a.Dim(0) != 4 || m["R"].Dim(0) != 6 {
+		t.Errorf("repeat changed source rows: %v", a.Shapes())
+	}
+}
+
+func TestVocabFromTable(t *testing.T) {
+	dt := etable.New(etable.Schema{
+		{Name: "Name", Type: etensor.STRING},
+		{Name: "Input", Type: etensor.FLOAT64, CellShape: []int{2, 3}},
+	}, 3)
+	for rw := 0; rw < 3; rw++ {
+		dt.SetCellTensorFloat1D("Input", rw, rw, 1)
+	}
+	m := make(Vocab)
+	tsr, err := AddVocabFromTable(m, "In", dt, "Input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tsr.Shapes(), []int{3, 2, 3}) || tsr.Value([]int{2, 0, 2}) != 1 || NOnInTensor(tsr) != 3 {
+		t.Errorf("from table: %v", tsr)
+	}
+	if _, err := AddVocabFromTable(m, "Nm", dt, "Name"); err == nil {
+		t.Errorf("expected error for scalar column")
+	}
+	if _, err := AddVocabFromTable(m, "X", dt, "Nope"); err == nil {
+		t.Errorf("expected error for missing column")
+	}
+}
+
+func TestVocabKMeans(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	m := make(Vocab)
+	AddVocabDistinct(m, "P", 3, 6, 6, 0.25, 14, 1000, nil)
+	AddVocabRepeat(m, "A", 5, "P", 0)
+	AddVocabRepeat(m, "B", 5, "P", 1)
+	AddVocabRepeat(m, "C", 5, "P", 2)
+	VocabConcat(m, "ABC", []string{"A", "B", "C"})
+	FlipBitsRows(m["ABC"], 1, 1, 1, 0)
+	trueLbls := []int{0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 2}
+	best := 0.0
+	for i := 0; i < 5; i++ { // k-means can get stuck in local minima
+		labels, ctrs, err := VocabKMeans(m, "ABC", 3, 20, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ctrs.Shapes(), []int{3, 6, 6}) || len(labels) != 15 {
+			t.Errorf("shapes: %v %d", ctrs.Shapes(), len(labels))
+		}
+		if pur := VocabKMeansPurity(labels, trueLbls); pur > best {
+			best = pur
+		}
+	}
+	if best != 1 {
+		t.Errorf("best purity: %v", best)
+	}
+	if pur := VocabKMeansPurity([]int{0, 0, 1, 1}, []int{0, 1, 1, 1}); pur != 0.75 {
+		t.Errorf("purity: %v", pur)
+	}
+	if _, _, err := VocabKMeans(m, "ABC", 20, 20, rng); err == nil {
+		t.Errorf("expected error for k > rows")
+	}
+}
+
+func TestVocabMix(t *testing.T) {
+	m := make(Vocab)
+	AddVocabPermutedBinary(m, "A", 10, 5, 5, 0.2, 0.1)
+	AddVocabPermutedBinary(m, "B", 10, 5, 5, 0.2, 0.1)
+	mx, err := AddVocabMix(m, "AB", "A", "B", 0.6, rand.New(rand.NewSource(5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rw := 0; rw < 10; rw++ {
+		row := mx.SubSpace([]int{rw}).(*etensor.Float32)
+		if n := NOnInTensor(row); n != 5 {
+			t.Errorf("row %d: n on: %d != 5", rw, n)
+		}
+		na, nb := 0, 0
+		for i, v := range row.Values {
+			if v == 0 {
+				continue
+			}
+			ina := m["A"].Values[rw*25+i] == 1
+			inb := m["B"].Values[rw*25+i] == 1
+			if !ina && !inb {
+				t.Errorf("row %d: bit %d not in either source", rw, i)
+			}
+			if ina {
+				na++
+			}
+			if inb {
+				nb++
+			}
+		}
+		if na < 3 {
+			t.Errorf("row %d: only %d bits from A", rw, na)
+		}
+	}
+	again, _ := AddVocabMix(m, "AB2", "A", "B", 0.6, rand.New(rand.NewSource(5)))
+	if !reflect.DeepEqual(again.Values, mx.Values) {
+		t.Errorf("same seed gave different mix")
+	}
+	// full overlap: all bits from A
+	mx, _ = AddVocabMix(m, "AA", "A", "A", 0.4, nil)
+	if !reflect.DeepEqual(mx.Values, m["A"].Values) {
+		t.Errorf("mix of A with itself should be A")
+	}
+	AddVocabEmpty(m, "E", 10, 4, 4)
+	if _, err := AddVocabMix(m, "AE", "A", "E", 0.5, nil); err == nil {
+		t.Errorf("expected shape mismatch error")
+	}
+}
+
+func TestVocabOverlap(t *testing.T) {
+	m := make(Vocab)
+	tsr := etensor.NewFloat32([]int{3, 2, 2}, nil, nil)
+	copy(tsr.Values, []float32{1, 1, 0, 0, 1, 0, 1, 0, 0, 0, 1, 1})
+	m["P"] = tsr
+	om, err := VocabPairwiseOverlapMatrix(m, "P")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trg := [][]float32{{2, 1, 0}, {1, 2, 1}, {0, 1, 2}}
+	if !reflect.DeepEqual(om, trg) {
+		t.Errorf("overlap matrix: %v != %v", om, trg)
+	}
+	min, max, mean, std, err := VocabPairwiseOverlapStats(m, "P")
+	if err != nil || min != 0 || max != 1 || mean != 2.0/3.0 || math.Abs(float64(std)-0.4714) > 1.0e-4 {
+		t.Errorf("overlap stats: %v %v %v %v %v", min, max, mean, std, err)
+	}
+}
+
+// naiveOverlapMatrix computes the overlap matrix using SubSpace rows, for comparison
+func naiveOverlapMatrix(tsr *etensor.Float32) [][]float32 {
+	rows := tsr.Dim(0)
+	om := make([][]float32, rows)
+	for i := range om {
+		om[i] = make([]float32, rows)
+		ri := tsr.SubSpace([]int{i}).(*etensor.Float32)
+		for j := 0; j < rows; j++ {
+			rj := tsr.SubSpace([]int{j}).(*etensor.Float32)
+			om[i][j] = metric.InnerProduct32(ri.Values, rj.Values)
+		}
+	}
+	return om
+}
+
+func benchVocab() Vocab {
+	m := make(Vocab)
+	AddVocabPermutedBinary(m, "P", 1000, 10, 10, 0.2, 0)
+	return m
+}
+
+func BenchmarkVocabOverlap(b *testing.B) {
+	m := benchVocab()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VocabPairwiseOverlapMatrix(m, "P")
+	}
+}
+
+func BenchmarkVocabOverlapNaive(b *testing.B) {
+	m := benchVocab()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		naiveOverlapMatrix(m["P"])
+	}
+}
+
+func TestVocabStream(t *testing.T) {
+	m := make(Vocab)
+	AddVocabPermutedBinary(m, "A", 10, 3, 3, 0.3, 0)
+	AddVocabPermutedBinary(m, "B", 10, 3, 3, 0.3, 0)
+	vs := NewVocabStream(m, 42)
+	if err := vs.AddEl("Input", 0, 0, "A", "B"); err != nil {
+		t.Fatal(err)
+	}
+	AddVocabEmpty(m, "C", 10, 2, 2)
+	if err := vs.AddEl("Bad", 0, 0, "A", "C"); err == nil {
+		t.Errorf("expected pool shape mismatch error")
+	}
+	els := vs.Elements()
+	if len(els) != 1 || !reflect.DeepEqual(els[0].Shape, []int{1, 2, 3, 3}) {
+		t.Errorf("elements: %v", els)
+	}
+	se := &env.StreamEnv{Nm: "Stream", Stream: vs}
+	se.Trial.Max = 5
+	se.Init(0)
+	var first [][]float32
+	for i := 0; i < 5; i++ {
+		se.Step()
+		pat := se.State("Input").(*etensor.Float32)
+		if n := NOnInTensor(pat); n != 6 {
+			t.Errorf("trial %d: n on: %d", i, n)
+		}
+		first = append(first, pat.Values)
+	}
+	if ep, _, _ := se.Counter(env.Epoch); ep != 0 {
+		t.Errorf("epoch: %d", ep)
+	}
+	se.Step()
+	if ep, _, chg := se.Counter(env.Epoch); ep != 1 || !chg {
+		t.Errorf("epoch: %d changed: %v", ep, chg)
+	}
+	se.Init(1) // replays same sequence
+	for i := 0; i < 5; i++ {
+		se.Step()
+		if pat := se.State("Input").(*etensor.Float32); !reflect.DeepEqual(pat.Values, first[i]) {
+			t.Errorf("trial %d: not replayed after Init", i)
+		}
+	}
+	vs.N = 3 // finite stream: epoch ends when exhausted
+	se.Trial.Max = 0
+	se.Init(2)
+	for i := 0; i < 4; i++ {
+		se.Step()
+	}
+	if ep, _, _ := se.Counter(env.Epoch); ep != 1 || se.Trial.Cur != 0 {
+		t.Errorf("finite stream epoch: %d trial: %d", ep, se.Trial.Cur)
+	}
+	if pat := se.State("Input").(*etensor.Float32); !reflect.DeepEqual(pat.Values, first[0]) {
+		t.Errorf("finite stream not reset at end of epoch")
+	}
+
+	m["E"] = etensor.NewFloat32([]int{0, 3, 3}, nil, nil)
+	if err := vs.AddEl("Empty", 0, 0, "A", "E"); err == nil {
+		t.Errorf("expected empty pool error")
+	}
+	if err := vs.Init(); err != nil {
+		t.Errorf("valid stream Init: %v", err)
+	}
+	m["B"] = m["E"] // emptied after AddEl
+	if err := vs.Init(); err == nil {
+		t.Errorf("expected empty pool error from Init")
+	}
+	if _, ok := vs.Next(); ok {
+		t.Errorf("Next with an empty pool should return false")
+	}
+}
+
+func TestVocabSample(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	m := make(Vocab)
+	src := etensor.NewFloat32([]int{4, 1, 2}, nil, nil)
+	copy(src.Values, []float32{0, 0, 1, 1, 2, 2, 3, 3}) // each row identified by its value
+	m["S"] = src
+	orig := append([]float32{}, src.Values...)
+	counts := make([]int, 4)
+	for i := 0; i < 100; i++ {
+		dst, err := VocabSampleWithReplacement(m, "S", "D", 40, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for r := 0; r < 40; r++ {
+			counts[int(dst.Values[r*2])]++
+		}
+	}
+	for r, c := range counts { // expected 1000 each
+		if c < 900 || c > 1100 {
+			t.Errorf("row %d sampled: %d times, expected about 1000", r, c)
+		}
+	}
+	dst, err := VocabSampleWithoutReplacement(m, "S", "W", 4, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[float32]bool)
+	for r := 0; r < 4; r++ {
+		seen[dst.Values[r*2]] = true
+	}
+	if len(seen) != 4 {
+		t.Errorf("without replacement repeated rows: %v", dst.Values)
+	}
+	if _, err := VocabSampleWithoutReplacement(m, "S", "W", 5, rng); err == nil {
+		t.Errorf("expected error for n > rows")
+	}
+	if _, err := VocabSampleWithoutReplacement(m, "S", "N", -1, rng); err == nil {
+		t.Errorf("expected error for n < 0 without replacement")
+	}
+	if _, err := VocabSampleWithReplacement(m, "S", "N", -1, rng); err == nil {
+		t.Errorf("expected error for n < 0 with replacement")
+	}
+	if _, has := m["N"]; has {
+		t.Errorf("pool added for n < 0")
+	}
+	if z, err := VocabSampleWithReplacement(m, "S", "Z", 0, rng); err != nil || z.Dim(0) != 0 {
+		t.Errorf("n = 0 should give an empty pool: %v", err)
+	}
+	if !reflect.DeepEqual(src.Values, orig) {
+		t.Errorf("source modified")
+	}
+}
+
+func TestVocabRepeatRows(t *testing.T) {
+	m := make(Vocab)
+	src, _ := AddVocabPermutedBinary(m, "A", 3, 2, 2, 0.5, 0)
+	rr, err := AddVocabRepeatRows(m, "R", "A", []int{2, 0, 0, 1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rr.Shapes(), []int{5, 2, 2}) {
+		t.Errorf("shape: %v", rr.Shapes())
+	}
+	for i, ri := range []int{2, 0, 0, 1, 2} {
+		if !reflect.DeepEqual(rr.Values[i*4:(i+1)*4], src.Values[ri*4:(ri+1)*4]) {
+			t.Errorf("row %d not copied from source row %d", i, ri)
+		}
+	}
+	if _, err := AddVocabRepeatRows(m, "R2", "A", []int{0, 3}); err == nil {
+		t.Errorf("expected out of range error")
+	}
+}
+
+func TestPartialCue(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	m := make(Vocab)
+	AddVocabPermutedBinary(m, "A", 1, 4, 5, 0.4, 0)
+	pat := m["A"].SubSpace([]int{0}).(*etensor.Float32)
+	cue := PartialCue(pat, 0.25, rng)
+	if n := NOnInTensor(cue); n != 6 {
+		t.Errorf("cue n on: %d != 6", n)
+	}
+	if NOnInTensor(pat) != 8 {
+		t.Errorf("source pattern modified")
+	}
+	if acc := CompletionAccuracy(pat, pat, 0.5); acc != 1 {
+		t.Errorf("perfect completion accuracy: %v", acc)
+	}
+	if acc := CompletionAccuracy(cue, pat, 0.5); acc != 0.75 {
+		t.Errorf("cue completion accuracy: %v", acc)
+	}
+}
+
+func TestVocabSequential(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	m := make(Vocab)
+	tsr, err := AddVocabSequential(m, "S", 40, 10, 10, 0.1, 0.6, 0.2, 5, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var with, betw float32
+	var nWith, nBetw int
+	for i := 0; i < 40; i++ {
+		ri := tsr.Values[i*100 : (i+1)*100]
+		if n := NOnInTensor(tsr.SubSpace([]int{i}).(*etensor.Float32)); n != 10 {
+			t.Errorf("row %d: n on: %d", i, n)
+		}
+		for j := i + 1; j < 40; j++ {
+			cs := metric.Cosine32(ri, tsr.Values[j*100:(j+1)*100])
+			if i/5 == j/5 {
+				with += cs
+				nWith++
+			} else {
+				betw += cs
+				nBetw++
+			}
+		}
+	}
+	with /= float32(nWith)
+	betw /= float32(nBetw)
+	if with < 0.6 || with > 0.7 || betw < 0.2 || betw > 0.3 {
+		t.Errorf("within sequence cosine: %g (0.6), between: %g (0.2)", with, betw)
+	}
+	if _, err := AddVocabSequential(m, "E", 10, 5, 5, 0.2, 0.1, 0.5, 5, rng); err == nil {
+		t.Errorf("expected error for withinSeqSim < betweenSeqSim")
+	}
+}
+
+func TestSliceRows(t *testing.T) {
+	tsr := etensor.NewFloat32([]int{5, 1, 2}, nil, nil)
+	for i := range tsr.Values {
+		tsr.Values[i] = float32(i)
+	}
+	sl, err := SliceRows(tsr, []int{1, 3, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sl) != 2 || !reflect.DeepEqual(sl[0].Values, []float32{2, 3, 4, 5}) || !reflect.DeepEqual(sl[1].Shapes(), []int{2, 1, 2}) {
+		t.Errorf("slices: %v", sl)
+	}
+	sl[0].Values[0] = 100
+	if tsr.Values[2] != 2 {
+		t.Errorf("slice shares memory with source")
+	}
+	if _, err := SliceRows(tsr, []int{0, 3, 2}); err == nil {
+		t.Errorf("expected error for decreasing offsets")
+	}
+	if _, err := SliceRows(tsr, []int{0, 6}); err == nil {
+		t.Errorf("expected error for out of range offset")
+	}
+}
+
+func TestVocabShifts(t *testing.T) {
+	m := make(Vocab)
+	base := etensor.NewFloat32([]int{1, 3, 3}, nil, nil)
+	base.Set([]int{0, 0, 2}, 1)
+	base.Set([]int{0, 1, 1}, 1)
+	m["B"] = base
+	sh, err := AddVocabShifts(m, "S", "B", 0, []int{0, 1, -1}, []int{0, 1, 0}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sh.Shapes(), []int{3, 3, 3}) || !reflect.DeepEqual(sh.Values[:9], base.Values) {
+		t.Errorf("unshifted row: %v", sh.Values[:9])
+	}
+	if sh.Value([]int{1, 1, 0}) != 1 || sh.Value([]int{1, 2, 2}) != 1 || NOnInTensor(sh.SubSpace([]int{1}).(*etensor.Float32)) != 2 {
+		t.Errorf("wrapped shift: %v", sh.Values[9:18])
+	}
+	if sh.Value([]int{2, 0, 1}) != 1 || sh.Value([]int{2, 1, 0}) != 1 {
+		t.Errorf("negative shift: %v", sh.Values[18:])
+	}
+	sh, _ = AddVocabShifts(m, "Z", "B", 0, []int{1}, []int{1}, false)
+	if sh.Value([]int{0, 2, 2}) != 1 || NOnInTensor(sh) != 1 {
+		t.Errorf("zero-fill shift: %v", sh.Values)
+	}
+	if _, err := AddVocabShifts(m, "E", "B", 0, []int{1, 2}, []int{1}, true); err == nil {
+		t.Errorf("expected error for inconsistent shifts")
+	}
+	if _, err := AddVocabShifts(m, "E", "B", 1, []int{1}, []int{1}, true); err == nil {
+		t.Errorf("expected error for invalid copyRow")
+	}
+}
+
+func TestVocabHierarchical(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	m := make(Vocab)
+	tree := TreeFromDepths(2, 2, []float32{0.3, 0.7}) // 4 leaves
+	if n := len(tree.Leaves()); n != 4 {
+		t.Fatalf("n leaves: %d", n)
+	}
+	tsr, err := AddVocabHierarchical(m, "H", 16, 10, 10, 0.2, tree, rng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sib, cous, other float32
+	var nSib, nCous, nOther int
+	for i := 0; i < 16; i++ {
+		ri := tsr.Values[i*100 : (i+1)*100]
+		if n := NOnInTensor(tsr.SubSpace([]int{i}).(*etensor.Float32)); n != 20 {
+			t.Errorf("row %d: n on: %d", i, n)
+		}
+		for j := i + 1; j < 16; j++ {
+			cs := metric.Cosine32(ri, tsr.Values[j*100:(j+1)*100])
+			switch {
+			case i/4 == j/4: // same leaf
+				sib += cs
+				nSib++
+			case i/8 == j/8: // same top-level category
+				cous += cs
+				nCous++
+			default:
+				other += cs
+				nOther++
+			}
+		}
+	}
+	sib /= float32(nSib)
+	cous /= float32(nCous)
+	other /= float32(nOther)
+	if !(sib > cous && cous > other) || sib < 0.7 || cous < 0.3 {
+		t.Errorf("similarity within leaf: %g, top-level category: %g, other: %g", sib, cous, other)
+	}
+	bad := &CategoryTree{ShareFrac: 0.5, Kids: []*CategoryTree{{ShareFrac: 0.2}}}
+	if _, err := AddVocabHierarchical(m, "B", 4, 5, 5, 0.2, bad, rng); err == nil {
+		t.Errorf("expected error for decreasing ShareFrac")
+	}
+	if _, err := AddVocabHierarchical(m, "N", 4, 5, 5, 0.2, nil, rng); err == nil {
+		t.Errorf("expected error for nil tree")
+	}
+	nilKid := &CategoryTree{ShareFrac: 0.2, Kids: []*CategoryTree{{ShareFrac: 0.5}, nil}}
+	if _, err := AddVocabHierarchical(m, "N", 4, 5, 5, 0.2, nilKid, rng); err == nil {
+		t.Errorf("expected error for nil subcategory")
+	}
+}
+
+func TestVocabConst(t *testing.T) {
+	m := make(Vocab)
+	c, err := AddVocabConst(m, "C", []int{2, 2, 3, 4, 5}, nil, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["C"] != c || !reflect.DeepEqual(c.Shapes(), []int{2, 2, 3, 4, 5}) || !reflect.DeepEqual(c.DimNames(), []string{"row", "PoolY", "PoolX", "Y", "X"}) {
+		t.Errorf("shape: %v names: %v", c.Shapes(), c.DimNames())
+	}
+	for _, v := range c.Values {
+		if v != 0.5 {
+			t.Fatalf("fill value: %v", v)
+		}
+	}
+	e, _ := AddVocabEmpty(m, "E", 3, 2, 2)
+	if !reflect.DeepEqual(e.Shapes(), []int{3, 2, 2}) || !reflect.DeepEqual(e.DimNames(), []string{"row", "Y", "X"}) || NOnInTensor(e) != 0 {
+		t.Errorf("empty: %v %v", e.Shapes(), e.Values)
+	}
+	if _, err := AddVocabConst(m, "B", []int{2, 0}, nil, 0); err == nil {
+		t.Errorf("expected error for zero size")
+	}
+	if _, err := AddVocabConst(m, "B", []int{2, 3}, []string{"row"}, 0); err == nil {
+		t.Errorf("expected error for dimNames mismatch")
+	}
+}
+
+func TestCompletionBench(t *testing.T) {
+	m := make(Vocab)
+	voc, err := AddVocabPermutedBinary(m, "A", 10, 5, 5, .2, .5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb := &CompletionBench{}
+	cb.Defaults()
+	cb.CueFracs = []float32{1, .6, .4}
+	cb.NTrials = 2
+	// nearest-neighbor auto-associator: returns the best-matching vocab pattern
+	cb.Trial = func(pat int, cue *etensor.Float32) (*etensor.Float32, error) {
+		best, bestOv := 0, float32(-1)
+		for ri := 0; ri < voc.Dim(0); ri++ {
+			row := voc.SubSpace([]int{ri}).(*etensor.Float32)
+			ov := metric.InnerProduct32(row.Values, cue.Values)
+			if ov > bestOv {
+				best, bestOv = ri, ov
+			}
+		}
+		return voc.SubSpace([]int{best}).(*etensor.Float32).Clone().(*etensor.Float32), nil
+	}
+	br, err := cb.Run(m, "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if br.NPats != 10 || len(br.CueFracs) != 3 || br.Capacity != 10 || br.Stopped {
+		t.Errorf("result: %+v", br)
+	}
+	if cr := br.CueFracs[0]; cr.Acc != 1 || cr.NRecalled != 10 || cr.Missed != 0 || cr.Spurious != 0 {
+		t.Errorf("full cue: %+v", cr)
+	}
+	if dt := br.Table(); dt.Rows != 3 || dt.CellFloat("Acc", 0) != 1 {
+		t.Errorf("table rows: %d", dt.Rows)
+	}
+
+	// identity: output is just the cue, so no completion
+	cb.Trial = func(pat int, cue *etensor.Float32) (*etensor.Float32, error) {
+		return cue, nil
+	}
+	cb.StopAcc = .5
+	cb.CueFracs = []float32{1, .6, .4, .2}
+	br, err = cb.Run(m, "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !br.Stopped || len(br.CueFracs) != 3 || br.Capacity != 10 {
+		t.Errorf("early stop: %v %d %d", br.Stopped, len(br.CueFracs), br.Capacity)
+	}
+	if cr := br.CueFracs[1]; math.Abs(float64(cr.Acc-.6)) > 1e-6 || cr.NRecalled != 0 || cr.Missed != 2 || cr.Spurious != 0 {
+		t.Errorf("partial cue: %+v", cr)
+	}
+
+	cb.Trial = func(pat int, cue *etensor.Float32) (*etensor.Float32, error) {
+		return nil, fmt.Errorf("trial failed")
+	}
+	if _, err := cb.Run(m, "A"); err == nil {
+		t.Errorf("expected error from Trial")
+	}
+	if _, err := cb.Run(m, "Missing"); err == nil {
+		t.Errorf("expected error for missing vocab")
+	}
+	cb.Trial = func(pat int, cue *etensor.Float32) (*etensor.Float32, error) {
+		return nil, nil
+	}
+	if _, err := cb.Run(m, "A"); err == nil || !strings.Contains(err.Error(), "no output") {
+		t.Errorf("expected error for nil Trial output: %v", err)
+	}
+
+	zb := &CompletionBench{CueFracs: []float32{1}, Trial: cb.Trial} // Defaults not called
+	if _, err := zb.Run(m, "A"); err == nil {
+		t.Errorf("expected error for NTrials 0")
+	}
+}
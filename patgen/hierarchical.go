@@ -0,0 +1,158 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package patgen
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/emer/etable/etensor"
+)
+
+// CategoryTree is a node in a tree of categories, used to specify the
+// similarity structure of patterns for AddVocabHierarchical.
+// The patterns are associated with the leaves of the tree, and patterns
+// in the same category share ShareFrac of their active bits, so that with
+// increasing ShareFrac at lower levels, siblings share more bits than cousins.
+type CategoryTree struct {
+	ShareFrac float32         `desc:"proportion of the active bits of each pattern that are shared by all the patterns in this category -- this includes the bits shared at higher levels, so it must be >= the ShareFrac of the parent"`
+	Kids      []*CategoryTree `desc:"subcategories -- a leaf has no Kids"`
+}
+
+// Leaves returns the leaves of the tree, in left-to-right order
+func (ct *CategoryTree) Leaves() []*CategoryTree {
+	if len(ct.Kids) == 0 {
+		return []*CategoryTree{ct}
+	}
+	var lvs []*CategoryTree
+	for _, kd := range ct.Kids {
+		lvs = append(lvs, kd.Leaves()...)
+	}
+	return lvs
+}
+
+// TreeFromDepths returns a balanced CategoryTree with nCategories subcategories
+// at each of depth levels below the root, where shareFracs gives the
+// ShareFrac for each level, from the top-level categories down to the leaves
+// (must have depth values, and should be increasing).  The root has a ShareFrac
+// of 0, so the top-level categories do not share any bits beyond chance.
+func TreeFromDepths(nCategories, depth int, shareFracs []float32) *CategoryTree {
+	root := &CategoryTree{}
+	treeFromDepths(root, nCategories, depth, shareFracs)
+	return root
+}
+
+func treeFromDepths(ct *CategoryTree, nCategories, depth int, shareFracs []float32) {
+	if depth == 0 || len(shareFracs) == 0 {
+		return
+	}
+	ct.Kids = make([]*CategoryTree, nCategories)
+	for i := range ct.Kids {
+		kd := &CategoryTree{ShareFrac: shareFracs[0]}
+		treeFromDepths(kd, nCategories, depth-1, shareFracs[1:])
+		ct.Kids[i] = kd
+	}
+}
+
+// AddVocabHierarchical adds a pool to the vocabulary with hierarchical
+// similarity structure given by the category tree: the rows are divided
+// among the leaves of the tree in order (as evenly as possible), and the
+// patterns within each category share the ShareFrac proportion of their
+// active bits.  The bits are assigned top-down, with each category adding
+// its own shared bits to those of its parent, and then each row filling in
+// the rest of its active bits at random.  Uses given rng, or a new one
+// seeded from the standard math/rand source if nil.  Returns an error if
+// the tree (or any subcategory) is nil, or ShareFrac decreases from any
+// category to one of its subcategories.
+func AddVocabHierarchical(mp Vocab, name string, rows, poolY, poolX int, pctAct float32, tree *CategoryTree, rng *rand.Rand) (*etensor.Float32, error) {
+	if err := checkShareFracs(tree, 0); err != nil {
+		return nil, err
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	cells := poolY * poolX
+	nOn := NFmPct(pctAct, cells)
+	tsr := etensor.NewFloat32([]int{rows, poolY, poolX}, nil, []string{"row", "Y", "X"})
+	mp[name] = tsr
+	if rows == 0 || cells == 0 {
+		return tsr, nil
+	}
+	leafBits := make(map[*CategoryTree][]int)
+	hierBits(tree, nil, nOn, cells, leafBits, rng)
+	lvs := tree.Leaves()
+	nper := rows / len(lvs)
+	nxtra := rows % len(lvs)
+	rw := 0
+	for li, lf := range lvs {
+		n := nper
+		if li < nxtra {
+			n++
+		}
+		for i := 0; i < n; i++ {
+			row := tsr.Values[rw*cells : (rw+1)*cells]
+			for _, ci := range leafBits[lf] {
+				row[ci] = 1
+			}
+			for nRnd := nOn - len(leafBits[lf]); nRnd > 0; {
+				ci := rng.Intn(cells)
+				if row[ci] == 0 {
+					row[ci] = 1
+					nRnd--
+				}
+			}
+			rw++
+		}
+	}
+	return tsr, nil
+}
+
+// checkShareFracs returns an error if the ShareFrac of any node in the
+// tree is less than that of its parent (parFrac) or greater than 1.
+func checkShareFracs(ct *CategoryTree, parFrac float32) error {
+	if ct == nil {
+		err := fmt.Errorf("AddVocabHierarchical: category tree is nil")
+		log.Println(err)
+		return err
+	}
+	if ct.ShareFrac < parFrac || ct.ShareFrac > 1 {
+		err := fmt.Errorf("AddVocabHierarchical: category ShareFrac: %g must be between its parent's ShareFrac: %g and 1", ct.ShareFrac, parFrac)
+		log.Println(err)
+		return err
+	}
+	for _, kd := range ct.Kids {
+		if err := checkShareFracs(kd, ct.ShareFrac); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hierBits recursively assigns the shared bits for each category, adding
+// new random bits to those of the parent (parBits), and records the bits
+// for each of the leaves.
+func hierBits(ct *CategoryTree, parBits []int, nOn, cells int, leafBits map[*CategoryTree][]int, rng *rand.Rand) {
+	bits := append([]int{}, parBits...)
+	has := make(map[int]bool, len(bits))
+	for _, ci := range bits {
+		has[ci] = true
+	}
+	for nNew := NFmPct(ct.ShareFrac, nOn) - len(parBits); nNew > 0; {
+		ci := rng.Intn(cells)
+		if !has[ci] {
+			has[ci] = true
+			bits = append(bits, ci)
+			nNew--
+		}
+	}
+	if len(ct.Kids) == 0 {
+		leafBits[ct] = bits
+		return
+	}
+	for _, kd := range ct.Kids {
+		hierBits(kd, bits, nOn, cells, leafBits, rng)
+	}
+}
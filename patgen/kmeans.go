@@ -0,0 +1,157 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package patgen
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+)
+
+// VocabKMeans clusters the rows of the named vocabulary pool into k groups
+// using the k-means algorithm with the Hamming distance, treating the patterns
+// as binary.  The centroids are binary patterns, with each bit set to 1 if
+// at least half of the rows in the cluster have it on (which is the pattern
+// with the minimum total Hamming distance to all rows in the cluster).
+// The initial centroids are k distinct random rows, chosen using rng
+// (if nil, a new one is seeded from the standard math/rand source).
+// Returns the cluster label for each row, and the [k, pool shape...] centroids.
+// If the cluster assignments have not converged within maxIter iterations,
+// the current results are returned along with an error.
+func VocabKMeans(mp Vocab, name string, k int, maxIter int, rng *rand.Rand) (labels []int, centroids *etensor.Float32, err error) {
+	tsr, err := mp.ByNameTry(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, cells := tsr.RowCellSize()
+	if k < 1 || k > rows {
+		err = fmt.Errorf("VocabKMeans: k: %d must be between 1 and the number of rows: %d in pool: %s", k, rows, name)
+		log.Println(err)
+		return nil, nil, err
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	centroids = etensor.NewFloat32(append([]int{k}, tsr.Shapes()[1:]...), nil, tsr.DimNames())
+	row := func(i int) []float32 { return tsr.Values[i*cells : (i+1)*cells] }
+	ctr := func(c int) []float32 { return centroids.Values[c*cells : (c+1)*cells] }
+	for c, ri := range rng.Perm(rows)[:k] {
+		binarize(ctr(c), row(ri))
+	}
+	labels = make([]int, rows)
+	for i := range labels {
+		labels[i] = -1
+	}
+	sums := make([]float32, cells)
+	for itr := 0; itr < maxIter; itr++ {
+		changed := false
+		for ri := 0; ri < rows; ri++ {
+			mc := 0
+			md := float32(-1)
+			for c := 0; c < k; c++ {
+				d := metric.Hamming32(binarized(row(ri)), ctr(c))
+				if md < 0 || d < md {
+					md = d
+					mc = c
+				}
+			}
+			if labels[ri] != mc {
+				labels[ri] = mc
+				changed = true
+			}
+		}
+		if !changed {
+			return labels, centroids, nil
+		}
+		for c := 0; c < k; c++ {
+			for i := range sums {
+				sums[i] = 0
+			}
+			n := 0
+			for ri, lb := range labels {
+				if lb != c {
+					continue
+				}
+				n++
+				for i, v := range row(ri) {
+					if v > 0.5 {
+						sums[i]++
+					}
+				}
+			}
+			cv := ctr(c)
+			if n == 0 { // empty cluster: restart at a random row
+				binarize(cv, row(rng.Intn(rows)))
+				continue
+			}
+			for i, s := range sums {
+				if 2*s >= float32(n) {
+					cv[i] = 1
+				} else {
+					cv[i] = 0
+				}
+			}
+		}
+	}
+	err = fmt.Errorf("VocabKMeans: pool: %s cluster assignments did not converge in: %d iterations", name, maxIter)
+	log.Println(err)
+	return labels, centroids, err
+}
+
+// binarize sets dst to 1 where src > .5 and 0 otherwise
+func binarize(dst, src []float32) {
+	for i, v := range src {
+		if v > 0.5 {
+			dst[i] = 1
+		} else {
+			dst[i] = 0
+		}
+	}
+}
+
+// binarized returns src if it is already binary, otherwise a binarized copy
+func binarized(src []float32) []float32 {
+	for _, v := range src {
+		if v != 0 && v != 1 {
+			dst := make([]float32, len(src))
+			binarize(dst, src)
+			return dst
+		}
+	}
+	return src
+}
+
+// VocabKMeansPurity returns the purity of the cluster labels relative to the
+// true category labels for the same rows: the proportion of rows whose true
+// label is the most common true label in their cluster.  1 = each cluster
+// contains only one category.
+func VocabKMeansPurity(labels, trueLabels []int) float64 {
+	if len(labels) == 0 || len(labels) != len(trueLabels) {
+		return 0
+	}
+	counts := make(map[int]map[int]int)
+	for i, lb := range labels {
+		cc, ok := counts[lb]
+		if !ok {
+			cc = make(map[int]int)
+			counts[lb] = cc
+		}
+		cc[trueLabels[i]]++
+	}
+	tot := 0
+	for _, cc := range counts {
+		mx := 0
+		for _, n := range cc {
+			if n > mx {
+				mx = n
+			}
+		}
+		tot += mx
+	}
+	return float64(tot) / float64(len(labels))
+}
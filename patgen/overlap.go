@@ -0,0 +1,80 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package patgen
+
+import (
+	"math"
+
+	"github.com/chewxy/math32"
+)
+
+// VocabPairwiseOverlapMatrix returns the symmetric [rows][rows] matrix of
+// overlaps (dot products) between all pairs of rows in the named pool.
+// The diagonal holds the overlap of each row with itself (e.g., number of
+// active bits for binary patterns).  This operates directly on the
+// underlying values, without allocating SubSpace tensors per row, so it is
+// fast even for large vocabularies.
+func VocabPairwiseOverlapMatrix(mp Vocab, name string) ([][]float32, error) {
+	tsr, err := mp.ByNameTry(name)
+	if err != nil {
+		return nil, err
+	}
+	rows, cells := tsr.RowCellSize()
+	vals := tsr.Values
+	om := make([][]float32, rows)
+	buf := make([]float32, rows*rows)
+	for i := range om {
+		om[i] = buf[i*rows : (i+1)*rows]
+	}
+	for i := 0; i < rows; i++ {
+		ri := vals[i*cells : (i+1)*cells]
+		omi := om[i]
+		for j := i; j < rows; j++ {
+			rj := vals[j*cells : (j+1)*cells]
+			rj = rj[:len(ri)] // eliminates bounds checks in loop
+			dp := float32(0)
+			for k, v := range ri {
+				dp += v * rj[k]
+			}
+			omi[j] = dp
+			om[j][i] = dp
+		}
+	}
+	return om, nil
+}
+
+// VocabPairwiseOverlapStats returns the min, max, mean, and standard
+// deviation of the overlaps (dot products) between all distinct pairs of
+// rows in the named pool, which is the most common diagnostic for how
+// distinct a set of patterns is.  Returns all zeros if there are fewer than 2 rows.
+func VocabPairwiseOverlapStats(mp Vocab, name string) (min, max, mean, std float32, err error) {
+	om, err := VocabPairwiseOverlapMatrix(mp, name)
+	if err != nil {
+		return
+	}
+	rows := len(om)
+	if rows < 2 {
+		return
+	}
+	min = math32.MaxFloat32
+	max = -math32.MaxFloat32
+	var sum, ssq float64
+	for i := 0; i < rows; i++ {
+		for _, ov := range om[i][i+1:] {
+			min = math32.Min(min, ov)
+			max = math32.Max(max, ov)
+			sum += float64(ov)
+			ssq += float64(ov) * float64(ov)
+		}
+	}
+	n := float64(rows*(rows-1)) / 2
+	mn := sum / n
+	mean = float32(mn)
+	vr := ssq/n - mn*mn
+	if vr > 0 {
+		std = float32(math.Sqrt(vr))
+	}
+	return
+}
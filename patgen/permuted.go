@@ -125,3 +125,51 @@ func RowVsPrevDist32(tsr *etensor.Float32, row int, fun metric.Func32) (min, max
 	}
 	return
 }
+
+// PermutedBinaryMinDist treats the tensor as a column of rows as in a etable.Table
+// and sets each row to contain nOn onVal values and the remainder are offVal values,
+// using a permuted order of tensor elements (i.e., randomly shuffled or permuted).
+// Each row is resampled until it has a Hamming distance of at least minDist
+// from all previous rows (i.e., at least minDist values are different), trying
+// up to maxIters times per row.  Unlike PermutedBinaryMinDiff, this stops at the
+// first row that cannot be generated, returning an error identifying that row.
+// Uses given rng, or a new one seeded from the standard math/rand source if nil.
+func PermutedBinaryMinDist(tsr *etensor.Float32, nOn int, onVal, offVal float32, minDist, maxIters int, rng *rand.Rand) error {
+	rows, cells := tsr.RowCellSize()
+	if rows == 0 || cells == 0 {
+		return errors.New("empty tensor")
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	pord := rng.Perm(cells)
+	for rw := 0; rw < rows; rw++ {
+		stidx := rw * cells
+		got := false
+		for itr := 0; itr < maxIters; itr++ {
+			rng.Shuffle(cells, func(i, j int) { pord[i], pord[j] = pord[j], pord[i] })
+			for i := 0; i < cells; i++ {
+				if i < nOn {
+					tsr.Values[stidx+pord[i]] = onVal
+				} else {
+					tsr.Values[stidx+pord[i]] = offVal
+				}
+			}
+			if rw == 0 {
+				got = true
+				break
+			}
+			min, _ := RowVsPrevDist32(tsr, rw, metric.Hamming32)
+			if int(min) >= minDist {
+				got = true
+				break
+			}
+		}
+		if !got {
+			err := fmt.Errorf("PermutedBinaryMinDist: minimum distance of: %d could not be met for row: %d of: %d after: %d attempts", minDist, rw, rows, maxIters)
+			log.Println(err)
+			return err
+		}
+	}
+	return nil
+}
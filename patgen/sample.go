@@ -0,0 +1,82 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package patgen
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/emer/etable/etensor"
+)
+
+// VocabSampleWithReplacement adds a new pool named dstName to the vocabulary,
+// with n rows drawn uniformly at random with replacement from the srcName pool,
+// e.g., for bootstrap resampling.  Uses given rng, or a new one seeded from
+// the standard math/rand source if nil.  The source pool is not modified.
+// Returns an error if n < 0.
+func VocabSampleWithReplacement(mp Vocab, srcName, dstName string, n int, rng *rand.Rand) (*etensor.Float32, error) {
+	if n < 0 {
+		err := fmt.Errorf("VocabSampleWithReplacement: n: %d must be >= 0", n)
+		log.Println(err)
+		return nil, err
+	}
+	src, err := mp.ByNameTry(srcName)
+	if err != nil {
+		return nil, err
+	}
+	rows := src.Dim(0)
+	if rows == 0 && n > 0 {
+		err := fmt.Errorf("VocabSampleWithReplacement: source pool: %s has no rows", srcName)
+		log.Println(err)
+		return nil, err
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	idxs := make([]int, n)
+	for i := range idxs {
+		idxs[i] = rng.Intn(rows)
+	}
+	return vocabSampleRows(mp, src, dstName, idxs), nil
+}
+
+// VocabSampleWithoutReplacement adds a new pool named dstName to the vocabulary,
+// with n distinct rows drawn at random from the srcName pool, in random order.
+// Returns an error if n < 0 or n is greater than the number of rows in srcName.
+// Uses given rng, or a new one seeded from the standard math/rand source if nil.
+// The source pool is not modified.
+func VocabSampleWithoutReplacement(mp Vocab, srcName, dstName string, n int, rng *rand.Rand) (*etensor.Float32, error) {
+	if n < 0 {
+		err := fmt.Errorf("VocabSampleWithoutReplacement: n: %d must be >= 0", n)
+		log.Println(err)
+		return nil, err
+	}
+	src, err := mp.ByNameTry(srcName)
+	if err != nil {
+		return nil, err
+	}
+	rows := src.Dim(0)
+	if n > rows {
+		err := fmt.Errorf("VocabSampleWithoutReplacement: n: %d is greater than the number of rows: %d in pool: %s", n, rows, srcName)
+		log.Println(err)
+		return nil, err
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	return vocabSampleRows(mp, src, dstName, rng.Perm(rows)[:n]), nil
+}
+
+// vocabSampleRows adds a new pool named dstName with copies of given rows of src
+func vocabSampleRows(mp Vocab, src *etensor.Float32, dstName string, idxs []int) *etensor.Float32 {
+	_, cells := src.RowCellSize()
+	tsr := etensor.NewFloat32(append([]int{len(idxs)}, src.Shapes()[1:]...), nil, src.DimNames())
+	for i, ri := range idxs {
+		copy(tsr.Values[i*cells:(i+1)*cells], src.Values[ri*cells:(ri+1)*cells])
+	}
+	mp[dstName] = tsr
+	return tsr
+}
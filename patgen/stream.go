@@ -0,0 +1,168 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package patgen
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etensor"
+)
+
+// StreamEl specifies one element (pattern) generated by a VocabStream,
+// which is composed of a grid of pools, each filled from a randomly
+// selected row of the corresponding vocabulary pool, as in MixPats.
+type StreamEl struct {
+	Name   string   `desc:"name of the element, e.g., the name of the layer it is to be applied to"`
+	PoolsY int      `desc:"number of pools in the Y dimension -- if 0, then all Pools are arranged in one row"`
+	PoolsX int      `desc:"number of pools in the X dimension"`
+	Pools  []string `desc:"names of the vocabulary pools to use for each pool in the element, in row-major order (left right, bottom up) -- must all have the same inner (pool) shape"`
+}
+
+// VocabStream is an env.Stream that generates patterns on the fly by mixing
+// randomly selected rows of vocabulary pools, using a random number generator
+// seeded with Seed, so that the same sequence is replayed after each Reset.
+// It does not need to store the patterns, so it can generate effectively
+// infinite streams of novel combinations of the vocabulary items.
+// Use with env.StreamEnv to present these to a model.
+type VocabStream struct {
+	Vocab Vocab      `desc:"the vocabulary of pools to draw patterns from"`
+	Els   []StreamEl `desc:"the elements to generate on each trial"`
+	Seed  int64      `desc:"random seed used to initialize the random number generator on Reset"`
+	N     int        `desc:"number of trials to generate before the stream is exhausted -- 0 = infinite"`
+	Trial int        `inactive:"+" desc:"number of trials generated since Reset"`
+	Rand  *rand.Rand `view:"-" desc:"random number generator"`
+}
+
+// NewVocabStream returns a new VocabStream for given vocabulary and seed,
+// which must then be configured with Els (see AddEl).
+func NewVocabStream(mp Vocab, seed int64) *VocabStream {
+	vs := &VocabStream{Vocab: mp, Seed: seed}
+	vs.Reset()
+	return vs
+}
+
+// AddEl adds an element with given name, composed of the given vocabulary
+// pools arranged in a poolsY x poolsX grid of pools (0, 0 = one row).
+// Returns an error if any pool is not found or has a different shape.
+func (vs *VocabStream) AddEl(name string, poolsY, poolsX int, pools ...string) error {
+	el := StreamEl{Name: name, PoolsY: poolsY, PoolsX: poolsX, Pools: pools}
+	if _, err := vs.ElShape(&el); err != nil {
+		return err
+	}
+	vs.Els = append(vs.Els, el)
+	return nil
+}
+
+// ElShape returns the shape of the patterns for given element:
+// [PoolsY, PoolsX, poolY, poolX].  Returns an error if any pool
+// is not found or empty, or the pools do not all have the same shape.
+func (vs *VocabStream) ElShape(el *StreamEl) ([]int, error) {
+	py, px := el.PoolsY, el.PoolsX
+	if py == 0 && px == 0 {
+		py, px = 1, len(el.Pools)
+	}
+	if py*px != len(el.Pools) || len(el.Pools) == 0 {
+		err := fmt.Errorf("VocabStream: element: %s number of pools: %d does not match pools Y x X: %d x %d", el.Name, len(el.Pools), py, px)
+		log.Println(err)
+		return nil, err
+	}
+	var pshp []int
+	for _, pnm := range el.Pools {
+		tsr, err := vs.Vocab.ByNameTry(pnm)
+		if err != nil {
+			return nil, err
+		}
+		if tsr == nil || tsr.Dim(0) == 0 {
+			err := fmt.Errorf("VocabStream: element: %s pool: %s has no rows", el.Name, pnm)
+			log.Println(err)
+			return nil, err
+		}
+		if pshp == nil {
+			pshp = tsr.Shapes()[1:]
+		} else if !etensor.EqualInts(pshp, tsr.Shapes()[1:]) {
+			err := fmt.Errorf("VocabStream: element: %s pool: %s shape: %v does not match others: %v", el.Name, pnm, tsr.Shapes()[1:], pshp)
+			log.Println(err)
+			return nil, err
+		}
+	}
+	return append([]int{py, px}, pshp...), nil
+}
+
+// Init checks that the pools of all the elements are valid (see ElShape),
+// e.g., after the Vocab has been modified since AddEl, and then Resets.
+// Returns the first error found, in which case Next would not generate
+// any patterns.
+func (vs *VocabStream) Init() error {
+	for ei := range vs.Els {
+		if _, err := vs.ElShape(&vs.Els[ei]); err != nil {
+			return err
+		}
+	}
+	vs.Reset()
+	return nil
+}
+
+// Reset re-initializes the random number generator from the Seed,
+// so that the same sequence of patterns is generated again.
+func (vs *VocabStream) Reset() {
+	vs.Rand = rand.New(rand.NewSource(vs.Seed))
+	vs.Trial = 0
+}
+
+// Next returns newly-generated patterns for each of the elements,
+// or false if N trials have already been generated, or an element
+// is not valid (see Init).
+func (vs *VocabStream) Next() (map[string]*etensor.Float32, bool) {
+	if vs.N > 0 && vs.Trial >= vs.N {
+		return nil, false
+	}
+	if vs.Rand == nil {
+		vs.Reset()
+	}
+	pats := make(map[string]*etensor.Float32, len(vs.Els))
+	for ei := range vs.Els {
+		el := &vs.Els[ei]
+		shp, err := vs.ElShape(el)
+		if err != nil {
+			return nil, false
+		}
+		tsr := etensor.NewFloat32(shp, nil, streamDimNames(shp))
+		cells := tsr.Len() / len(el.Pools)
+		for pi, pnm := range el.Pools {
+			src := vs.Vocab[pnm]
+			row := vs.Rand.Intn(src.Dim(0))
+			copy(tsr.Values[pi*cells:(pi+1)*cells], src.Values[row*cells:(row+1)*cells])
+		}
+		pats[el.Name] = tsr
+	}
+	vs.Trial++
+	return pats, true
+}
+
+// Elements returns the names and shapes of the elements generated
+func (vs *VocabStream) Elements() env.Elements {
+	els := make(env.Elements, 0, len(vs.Els))
+	for ei := range vs.Els {
+		el := &vs.Els[ei]
+		shp, _ := vs.ElShape(el)
+		els = append(els, env.Element{Name: el.Name, Shape: shp, DimNames: streamDimNames(shp)})
+	}
+	return els
+}
+
+// streamDimNames returns the dimension names for given element shape,
+// if it has the standard 4D shape, otherwise nil
+func streamDimNames(shp []int) []string {
+	if len(shp) != 4 {
+		return nil
+	}
+	return []string{"PoolY", "PoolX", "Y", "X"}
+}
+
+// Compile-time check that implements Stream interface
+var _ env.Stream = (*VocabStream)(nil)
@@ -0,0 +1,188 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prjn
+
+import (
+	"math/rand"
+
+	"github.com/emer/emergent/evec"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/mat32"
+)
+
+// Gaussian implements a topographic pattern of connectivity between two layers
+// where the connection strength falls off as a 2D Gaussian function of the
+// distance between the normalized positions of the receiving and sending units
+// (normalized so the entire width / height of each layer is 1.0, which allows
+// layers of different sizes to be mapped onto each other).
+// Connections are made where the gaussian value is >= MinP, or, if Rnd is set,
+// with probability equal to the gaussian value.
+// 4D layers are automatically flattened to 2D for this connection, unless
+// Pools is set, in which case positions are computed over pools.
+type Gaussian struct {
+	Sigma   float32 `def:"0.2" desc:"gaussian sigma (width) in normalized units where entire distance across the sending layer is 1.0"`
+	CtrMove float32 `def:"0.8,1" desc:"proportion to move gaussian center relative to the position of the receiving unit: 1.0 = centers span the entire range of the sending layer.  Typically want to use 1.0 for Wrap = true, and 0.8 for false"`
+	Wrap    bool    `desc:"if true, gaussian wraps around the edges of the sending layer, with the closest distance being used"`
+	Pools   bool    `desc:"if true, and both layers are 4D, then positions are computed in terms of pools, and all the units within connected pools are connected"`
+	MinP    float32 `def:"0.1" min:"0" max:"1" desc:"minimum gaussian value for making a connection -- nothing is connected below this value, which determines the effective extent of the connectivity"`
+	Rnd     bool    `desc:"if true, connections at or above MinP are made with probability equal to the gaussian value, using RndSeed for replicable results -- otherwise all are connected"`
+	RndSeed int64   `view:"-" desc:"the current random seed -- if zero when Connect is called, a new seed is generated"`
+	SelfCon bool    `desc:"if true, and connecting layer to itself (self projection), then make a self-connection from unit to itself"`
+}
+
+func NewGaussian() *Gaussian {
+	gp := &Gaussian{}
+	gp.Defaults()
+	return gp
+}
+
+func (gp *Gaussian) Defaults() {
+	gp.Sigma = 0.2
+	gp.CtrMove = 1
+	gp.Wrap = true
+	gp.MinP = 0.1
+}
+
+func (gp *Gaussian) Name() string {
+	return "Gaussian"
+}
+
+func (gp *Gaussian) Connect(send, recv *etensor.Shape, same bool) (sendn, recvn *etensor.Int32, cons *etensor.Bits) {
+	sendn, recvn, cons = NewTensors(send, recv)
+	if gp.Rnd && gp.RndSeed == 0 {
+		gp.RndSeed = int64(rand.Uint64())
+	}
+	rnd := rand.New(rand.NewSource(gp.RndSeed))
+
+	rnv := recvn.Values
+	snv := sendn.Values
+	sNtot := send.Len()
+
+	if gp.Pools && send.NumDims() == 4 && recv.NumDims() == 4 {
+		sNpY, sNpX := send.Dim(0), send.Dim(1)
+		rNpY, rNpX := recv.Dim(0), recv.Dim(1)
+		sNu := send.Dim(2) * send.Dim(3)
+		rNu := recv.Dim(2) * recv.Dim(3)
+		for rpy := 0; rpy < rNpY; rpy++ {
+			for rpx := 0; rpx < rNpX; rpx++ {
+				rpos := NormPos(rpx, rpy, rNpX, rNpY)
+				ris := (rpy*rNpX + rpx) * rNu
+				for spy := 0; spy < sNpY; spy++ {
+					for spx := 0; spx < sNpX; spx++ {
+						g := gp.Gauss(rpos, NormPos(spx, spy, sNpX, sNpY))
+						if g < gp.MinP {
+							continue
+						}
+						sis := (spy*sNpX + spx) * sNu
+						for rui := 0; rui < rNu; rui++ {
+							ri := ris + rui
+							for sui := 0; sui < sNu; sui++ {
+								si := sis + sui
+								if !gp.SelfCon && same && ri == si {
+									continue
+								}
+								if gp.Rnd && rnd.Float32() >= g {
+									continue
+								}
+								cons.Values.Set(ri*sNtot+si, true)
+								rnv[ri]++
+								snv[si]++
+							}
+						}
+					}
+				}
+			}
+		}
+		return
+	}
+
+	sNy, sNx, _, _ := etensor.Prjn2DShape(send, false)
+	rNy, rNx, _, _ := etensor.Prjn2DShape(recv, false)
+	for ry := 0; ry < rNy; ry++ {
+		for rx := 0; rx < rNx; rx++ {
+			rpos := NormPos(rx, ry, rNx, rNy)
+			ri := etensor.Prjn2DIdx(recv, false, ry, rx)
+			for sy := 0; sy < sNy; sy++ {
+				for sx := 0; sx < sNx; sx++ {
+					si := etensor.Prjn2DIdx(send, false, sy, sx)
+					if !gp.SelfCon && same && ri == si {
+						continue
+					}
+					g := gp.Gauss(rpos, NormPos(sx, sy, sNx, sNy))
+					if g < gp.MinP {
+						continue
+					}
+					if gp.Rnd && rnd.Float32() >= g {
+						continue
+					}
+					cons.Values.Set(ri*sNtot+si, true)
+					rnv[ri]++
+					snv[si]++
+				}
+			}
+		}
+	}
+	return
+}
+
+// Gauss returns the gaussian value for given normalized receiving and sending
+// positions, moving the center according to CtrMove and using the closest
+// wrapped-around distance if Wrap is set.
+func (gp *Gaussian) Gauss(rpos, spos mat32.Vec2) float32 {
+	ctr := rpos.SubScalar(0.5).MulScalar(gp.CtrMove).AddScalar(0.5)
+	if gp.Wrap {
+		spos.X = WrapMinDist(spos.X, 1, ctr.X)
+		spos.Y = WrapMinDist(spos.Y, 1, ctr.Y)
+	}
+	return evec.GaussVecDistNoNorm(spos, ctr, gp.Sigma)
+}
+
+// GaussWts returns gaussian weight value for given unit indexes in
+// given send and recv layers according to the Gaussian parameters.
+// Can be used for a Prjn.SetScalesFunc or SetWtsFunc
+func (gp *Gaussian) GaussWts(si, ri int, send, recv *etensor.Shape) float32 {
+	if gp.Pools && send.NumDims() == 4 && recv.NumDims() == 4 {
+		sNpX := send.Dim(1)
+		rNpX := recv.Dim(1)
+		spi := si / (send.Dim(2) * send.Dim(3))
+		rpi := ri / (recv.Dim(2) * recv.Dim(3))
+		rpos := NormPos(rpi%rNpX, rpi/rNpX, rNpX, recv.Dim(0))
+		spos := NormPos(spi%sNpX, spi/sNpX, sNpX, send.Dim(0))
+		return gp.Gauss(rpos, spos)
+	}
+	sNy, sNx, _, _ := etensor.Prjn2DShape(send, false)
+	rNy, rNx, _, _ := etensor.Prjn2DShape(recv, false)
+	sy, sx := Prjn2DPos(send, si)
+	ry, rx := Prjn2DPos(recv, ri)
+	return gp.Gauss(NormPos(rx, ry, rNx, rNy), NormPos(sx, sy, sNx, sNy))
+}
+
+// NormPos returns the normalized (0-1) position of the center of
+// given x, y coordinate in a space of size nx, ny.
+func NormPos(x, y, nx, ny int) mat32.Vec2 {
+	return mat32.NewVec2((float32(x)+0.5)/float32(nx), (float32(y)+0.5)/float32(ny))
+}
+
+// Prjn2DPos returns the row, col position in the 2D projection of given
+// shape (see etensor.Prjn2DShape) for given 1D unit index -- this is the
+// inverse of etensor.Prjn2DIdx (for oddRow = false).
+func Prjn2DPos(shp *etensor.Shape, idx int) (row, col int) {
+	switch shp.NumDims() {
+	case 1:
+		return 0, idx
+	case 2:
+		nx := shp.Dim(1)
+		return idx / nx, idx % nx
+	case 4:
+		npx := shp.Dim(1)
+		nny := shp.Dim(2)
+		nnx := shp.Dim(3)
+		nu := nny * nnx
+		pi := idx / nu
+		ui := idx % nu
+		return (pi/npx)*nny + ui/nnx, (pi%npx)*nnx + ui%nnx
+	}
+	return 0, idx
+}
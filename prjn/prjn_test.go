@@ -284,3 +284,83 @@ func TestUnifRndSelf(t *testing.T) {
 	fmt.Printf("sendn: %v\n", sendn.Values)
 	fmt.Printf("unif rnd rNtot: %d  pcon: %g  max: %d  min: %d  mean: %g\n", rNtot, pj.PCon, nrMax, nrMin, float32(nrMean)/float32(sNtot))
 }
+
+func TestGaussian(t *testing.T) {
+	send := etensor.NewShape([]int{4, 4}, nil, nil)
+	recv := etensor.NewShape([]int{4, 4}, nil, nil)
+
+	pj := NewGaussian()
+	pj.Sigma = 0.1
+	pj.MinP = 0.5
+	pj.Wrap = false
+	sendn, recvn, cons := pj.Connect(send, recv, false)
+	fmt.Printf("gaussian recv: 4x4 send: 4x4\n%s\n", string(ConsStringFull(send, recv, cons)))
+
+	// with narrow sigma and no wrap, each unit only connects to itself
+	CheckAllN(sendn, 1, t)
+	CheckAllN(recvn, 1, t)
+	for i := 0; i < send.Len(); i++ {
+		if !cons.Value1D(i*send.Len() + i) {
+			t.Errorf("gaussian: unit %d not connected to its topographic counterpart\n", i)
+		}
+	}
+
+	pj.Sigma = 0.3
+	pj.Wrap = true
+	sendn, recvn, cons = pj.Connect(send, recv, false)
+	// with wrap, every unit has the same neighborhood
+	CheckAllN(sendn, int(sendn.Values[0]), t)
+	CheckAllN(recvn, int(recvn.Values[0]), t)
+
+	psend := etensor.NewShape([]int{2, 2, 2, 3}, nil, nil)
+	precv := etensor.NewShape([]int{2, 2, 1, 2}, nil, nil)
+	pj.Pools = true
+	pj.Sigma = 0.1
+	sendn, recvn, cons = pj.Connect(psend, precv, false)
+	fmt.Printf("gaussian pools recv: 2x2x1x2 send: 2x2x2x3\n%s\n", string(ConsStringFull(psend, precv, cons)))
+	CheckAllN(sendn, 2, t)
+	CheckAllN(recvn, 6, t)
+
+	for ri := 0; ri < precv.Len(); ri++ {
+		for si := 0; si < psend.Len(); si++ {
+			wt := pj.GaussWts(si, ri, psend, precv)
+			if (wt >= pj.MinP) != cons.Value1D(ri*psend.Len()+si) {
+				t.Errorf("gaussian: GaussWts: %g inconsistent with connectivity for si: %d ri: %d\n", wt, si, ri)
+			}
+		}
+	}
+}
+
+func TestUnifRndNCon(t *testing.T) {
+	send := etensor.NewShape([]int{20, 30}, nil, nil)
+	recv := etensor.NewShape([]int{30, 40}, nil, nil)
+
+	pj := NewUnifRnd()
+	pj.NCon = 17
+	sendn, recvn, cons := pj.Connect(send, recv, false)
+	CheckAllN(recvn, pj.NCon, t)
+
+	// same seed must produce identical connectivity
+	sendn2, _, cons2 := pj.Connect(send, recv, false)
+	for i := 0; i < cons.Len(); i++ {
+		if cons.Value1D(i) != cons2.Value1D(i) {
+			t.Errorf("unif rnd ncon: connectivity not replicable with same seed at idx: %d\n", i)
+			break
+		}
+	}
+	for i := range sendn.Values {
+		if sendn.Values[i] != sendn2.Values[i] {
+			t.Errorf("unif rnd ncon: sendn not replicable with same seed at idx: %d\n", i)
+			break
+		}
+	}
+
+	// self projection: NCon is clamped to the available senders
+	self := etensor.NewShape([]int{2, 3}, nil, nil)
+	pj = NewUnifRnd()
+	pj.NCon = 10
+	sendn, recvn, cons = pj.Connect(self, self, true)
+	fmt.Printf("unif rnd ncon self clamped: 2x3\n%s\n", string(ConsStringFull(self, self, cons)))
+	CheckAllN(recvn, self.Len()-1, t)
+	CheckAllN(sendn, self.Len()-1, t)
+}
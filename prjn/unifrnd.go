@@ -5,6 +5,7 @@
 package prjn
 
 import (
+	"log"
 	"math"
 	"math/rand"
 	"sort"
@@ -17,9 +18,12 @@ import (
 // uses a permuted (shuffled) list for without-replacement randomness
 // and maintains its own local random seed for fully replicable results
 // (if seed is not set when run, then random number generator is used to create seed)
-// should reset seed after calling to resume sequence appropriately
+// should reset seed after calling to resume sequence appropriately.
+// Each receiving unit gets exactly the same number of sending connections
+// (fan-in), determined either by PCon or directly by NCon if that is > 0.
 type UnifRnd struct {
 	PCon    float32 `min:"0" max:"1" desc:"probability of connection (0-1)"`
+	NCon    int     `min:"0" desc:"if > 0, the exact number of sending connections to make for each receiving unit (fan-in), overriding PCon -- clamped to the number of available sending units"`
 	RndSeed int64   `view:"-" desc:"the current random seed"`
 	SelfCon bool    `desc:"if true, and connecting layer to itself (self projection), then make a self-connection from unit to itself"`
 	Recip   bool    `desc:"reciprocal connectivity: if true, switch the sending and receiving layers to create a symmetric top-down projection -- ESSENTIAL to use same RndSeed between two prjns to ensure symmetry"`
@@ -33,8 +37,22 @@ func (ur *UnifRnd) Name() string {
 	return "UnifRnd"
 }
 
+// NSend returns the number of sending connections to make for each receiving
+// unit, given the number of available sending units, using NCon if > 0
+// (clamped to navail, with a log message) and otherwise PCon.
+func (ur *UnifRnd) NSend(navail int) int {
+	if ur.NCon > 0 {
+		if ur.NCon > navail {
+			log.Printf("prjn.UnifRnd: NCon: %d is greater than number of available sending units: %d -- clamping\n", ur.NCon, navail)
+			return navail
+		}
+		return ur.NCon
+	}
+	return int(math.Round(float64(ur.PCon) * float64(navail)))
+}
+
 func (ur *UnifRnd) Connect(send, recv *etensor.Shape, same bool) (sendn, recvn *etensor.Int32, cons *etensor.Bits) {
-	if ur.PCon >= 1 {
+	if ur.PCon >= 1 && ur.NCon <= 0 {
 		return ur.ConnectFull(send, recv, same)
 	}
 	if ur.Recip {
@@ -45,12 +63,11 @@ func (ur *UnifRnd) Connect(send, recv *etensor.Shape, same bool) (sendn, recvn *
 	rlen := recv.Len()
 
 	noself := same && !ur.SelfCon
-	var nsend int
+	navail := slen
 	if noself {
-		nsend = int(math.Round(float64(ur.PCon) * float64(slen-1)))
-	} else {
-		nsend = int(math.Round(float64(ur.PCon) * float64(slen)))
+		navail--
 	}
+	nsend := ur.NSend(navail)
 
 	// NOTE: this is reasonably accurate: mean + 3 * SEM, but we can just use
 	// empirical values more easily and safely.
@@ -125,12 +142,11 @@ func (ur *UnifRnd) ConnectRecip(send, recv *etensor.Shape, same bool) (sendn, re
 	slenR := send.Len() // NOT swapped
 
 	noself := same && !ur.SelfCon
-	var nsend int
+	navail := slen
 	if noself {
-		nsend = int(math.Round(float64(ur.PCon) * float64(slen-1)))
-	} else {
-		nsend = int(math.Round(float64(ur.PCon) * float64(slen)))
+		navail--
 	}
+	nsend := ur.NSend(navail)
 
 	rnv := sendn.Values // swapped
 	for i := range rnv {
@@ -0,0 +1,102 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package rsa provides an accumulator for representational similarity analysis:
+it incrementally collects the mean activity pattern for each label
+(e.g., test item) and computes the representational dissimilarity matrix
+among these mean patterns, without storing every individual pattern.
+*/
+package rsa
+
+import (
+	"log"
+
+	"github.com/emer/etable/etensor"
+	"github.com/emer/etable/metric"
+)
+
+// RSA accumulates the mean activity pattern for each of a set of labels,
+// and computes the representational dissimilarity matrix (RDM) as
+// 1 - correlation between all pairs of mean patterns.
+// Labels can be presented any number of times, in any order -- repeated
+// presentations are averaged.  Call Init to set N and clear any existing data.
+type RSA struct {
+	N      int            `desc:"number of units in each activity pattern"`
+	Labels []string       `desc:"labels in the order in which they were first added -- this is the order of rows and columns in the Matrix"`
+	Counts []int          `desc:"number of patterns added for each label"`
+	Sums   [][]float32    `view:"-" desc:"sum of patterns for each label"`
+	LblMap map[string]int `view:"-" desc:"map of label to index in Labels"`
+}
+
+// Init initializes the accumulator for patterns of n units, removing
+// any existing data.
+func (rs *RSA) Init(n int) {
+	rs.N = n
+	rs.Labels = nil
+	rs.Counts = nil
+	rs.Sums = nil
+	rs.LblMap = make(map[string]int)
+}
+
+// Add adds the given activity pattern for given label.  The pattern must
+// have N values -- an error is logged and the pattern is ignored otherwise.
+func (rs *RSA) Add(label string, act []float32) {
+	if len(act) != rs.N {
+		log.Printf("rsa.RSA Add: label: %v pattern length: %d is not N: %d\n", label, len(act), rs.N)
+		return
+	}
+	if rs.LblMap == nil { // N set directly, without Init
+		rs.LblMap = make(map[string]int, len(rs.Labels))
+		for li, lbl := range rs.Labels {
+			rs.LblMap[lbl] = li
+		}
+	}
+	li, ok := rs.LblMap[label]
+	if !ok {
+		li = len(rs.Labels)
+		rs.LblMap[label] = li
+		rs.Labels = append(rs.Labels, label)
+		rs.Counts = append(rs.Counts, 0)
+		rs.Sums = append(rs.Sums, make([]float32, rs.N))
+	}
+	sum := rs.Sums[li]
+	for i, v := range act {
+		sum[i] += v
+	}
+	rs.Counts[li]++
+}
+
+// Mean returns the mean activity pattern for given label index
+func (rs *RSA) Mean(li int) []float32 {
+	mn := make([]float32, rs.N)
+	cn := float32(rs.Counts[li])
+	for i, v := range rs.Sums[li] {
+		mn[i] = v / cn
+	}
+	return mn
+}
+
+// Matrix returns the representational dissimilarity matrix, as
+// 1 - correlation between the mean patterns for each pair of labels,
+// with rows and columns in the order of Labels.
+func (rs *RSA) Matrix() *etensor.Float32 {
+	nl := len(rs.Labels)
+	mat := etensor.NewFloat32([]int{nl, nl}, nil, []string{"Label", "Label"})
+	mns := make([][]float32, nl)
+	for li := range mns {
+		mns[li] = rs.Mean(li)
+	}
+	for ai := 0; ai < nl; ai++ {
+		for bi := 0; bi <= ai; bi++ {
+			d := float32(0)
+			if ai != bi {
+				d = metric.InvCorrelation32(mns[ai], mns[bi])
+			}
+			mat.Values[ai*nl+bi] = d
+			mat.Values[bi*nl+ai] = d
+		}
+	}
+	return mat
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rsa
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRSA(t *testing.T) {
+	rs := &RSA{}
+	rs.Init(4)
+	rs.Add("a", []float32{1, 0, 0, 0})
+	rs.Add("b", []float32{1, 1, 0, 0})
+	rs.Add("a", []float32{1, 1, 0, 0}) // a mean = .5 diff from b
+	rs.Add("a", []float32{1, 0, 0, 0})
+	rs.Add("c", []float32{0, 0, 1, 1})
+	rs.Add("c", []float32{0, 0, 1}) // ignored
+	if len(rs.Labels) != 3 || rs.Counts[0] != 3 || rs.Counts[2] != 1 {
+		t.Errorf("labels: %v counts: %v", rs.Labels, rs.Counts)
+	}
+	mat := rs.Matrix()
+	if mat.Dim(0) != 3 || mat.Dim(1) != 3 {
+		t.Errorf("matrix shape: %v", mat.Shapes())
+	}
+	for i := 0; i < 3; i++ {
+		if mat.Value([]int{i, i}) != 0 {
+			t.Errorf("diagonal %d not 0: %v", i, mat.Value([]int{i, i}))
+		}
+	}
+	// b vs c are perfectly anti-correlated
+	if d := mat.Value([]int{1, 2}); math.Abs(float64(d)-2) > 1.0e-6 {
+		t.Errorf("b vs c: %v", d)
+	}
+	if mat.Value([]int{0, 1}) != mat.Value([]int{1, 0}) {
+		t.Errorf("not symmetric")
+	}
+	if ab, ac := mat.Value([]int{0, 1}), mat.Value([]int{0, 2}); ab >= ac {
+		t.Errorf("a should be more similar to b than c: %v vs. %v", ab, ac)
+	}
+}
+
+func TestRSANoInit(t *testing.T) {
+	rs := &RSA{N: 2}
+	rs.Add("a", []float32{1, 0})
+	rs.Add("b", []float32{0, 1})
+	rs.Add("a", []float32{0, 0})
+	if len(rs.Labels) != 2 || rs.Counts[0] != 2 || rs.Mean(0)[0] != 0.5 {
+		t.Errorf("labels: %v counts: %v", rs.Labels, rs.Counts)
+	}
+	var empty RSA
+	empty.Add("x", nil) // N = 0 matches an empty pattern
+	if len(empty.Labels) != 1 {
+		t.Errorf("labels: %v", empty.Labels)
+	}
+}
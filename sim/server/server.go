@@ -0,0 +1,196 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package server provides a simple HTTP / JSON server for remote monitoring
+and control of a running (typically headless) simulation:
+
+	GET  /layers                    list of layer names, types and shapes
+	GET  /layers/{name}/activations unit values for layer (var=Act by default, see ?var=)
+	POST /params                    apply the params.Sheet in the JSON body to the network
+	POST /step?n=N                  advance the simulation by N steps (default 1, at most MaxSteps)
+
+What a "step" is depends entirely on the simulation, which provides the
+StepFunc that does it.  All network access from the server is protected by
+the Mu mutex, which the simulation must also lock (Mu.Lock) while updating
+the network in its own run loop, so that HTTP reads are interleaved safely.
+*/
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
+)
+
+// LayerInfo is the JSON response for each layer in GET /layers
+type LayerInfo struct {
+	Name  string
+	Type  string
+	Shape []int
+}
+
+// LayerVals is the JSON response for GET /layers/{name}/activations
+type LayerVals struct {
+	Name  string
+	Var   string
+	Shape []int
+	Vals  []float32
+}
+
+// DefMaxSteps is the default SimServer.MaxSteps
+const DefMaxSteps = 1000
+
+// SimServer serves HTTP requests for monitoring and controlling a network.
+// It implements http.Handler, so it can be used directly with
+// http.ListenAndServe, or via the Serve method.
+type SimServer struct {
+	Net      emer.Network      `desc:"the network being monitored"`
+	StepFunc func(n int) error `desc:"function that advances the simulation by n steps -- if nil, POST /step returns an error"`
+	MaxSteps int               `def:"1000" desc:"maximum number of steps in one POST /step request -- larger requests are rejected, as the network is locked for the whole request (0 = DefMaxSteps)"`
+	Mu       sync.RWMutex      `desc:"mutex protecting the network: the server does RLock for reads and Lock for params and steps -- the simulation must Lock while updating the network itself"`
+}
+
+// NewSimServer returns a new server for given network, using given
+// function to advance the simulation (can be nil).
+func NewSimServer(net emer.Network, stepFunc func(n int) error) *SimServer {
+	return &SimServer{Net: net, StepFunc: stepFunc, MaxSteps: DefMaxSteps}
+}
+
+// Serve listens on given TCP address (e.g., ":8080") and serves requests.
+// It blocks until the server fails, so typically is called in a goroutine.
+func (ss *SimServer) Serve(addr string) error {
+	return http.ListenAndServe(addr, ss)
+}
+
+// ServeHTTP dispatches requests to the endpoints
+func (ss *SimServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "layers":
+		ss.methodOnly(w, r, http.MethodGet, ss.Layers)
+	case len(parts) == 3 && parts[0] == "layers" && parts[2] == "activations":
+		ss.methodOnly(w, r, http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+			ss.Activations(w, r, parts[1])
+		})
+	case path == "params":
+		ss.methodOnly(w, r, http.MethodPost, ss.Params)
+	case path == "step":
+		ss.methodOnly(w, r, http.MethodPost, ss.Step)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// methodOnly calls the handler only if the request uses given method
+func (ss *SimServer) methodOnly(w http.ResponseWriter, r *http.Request, method string, fun http.HandlerFunc) {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	fun(w, r)
+}
+
+// Layers handles GET /layers
+func (ss *SimServer) Layers(w http.ResponseWriter, r *http.Request) {
+	ss.Mu.RLock()
+	nl := ss.Net.NLayers()
+	lis := make([]LayerInfo, nl)
+	for li := 0; li < nl; li++ {
+		ly := ss.Net.Layer(li)
+		lis[li] = LayerInfo{Name: ly.Name(), Type: ly.Type().String(), Shape: ly.Shape().Shp}
+	}
+	ss.Mu.RUnlock()
+	writeJSON(w, lis)
+}
+
+// Activations handles GET /layers/{name}/activations, with an optional
+// var query parameter for the unit variable (Act by default)
+func (ss *SimServer) Activations(w http.ResponseWriter, r *http.Request, lnm string) {
+	vnm := r.URL.Query().Get("var")
+	if vnm == "" {
+		vnm = "Act"
+	}
+	ss.Mu.RLock()
+	ly, err := ss.Net.LayerByNameTry(lnm)
+	if err != nil {
+		ss.Mu.RUnlock()
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	lv := LayerVals{Name: lnm, Var: vnm, Shape: ly.Shape().Shp}
+	err = ly.UnitVals(&lv.Vals, vnm)
+	ss.Mu.RUnlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, lv)
+}
+
+// Params handles POST /params, applying the params.Sheet in the body to
+// the network.  Responds with whether any params were applied.
+func (ss *SimServer) Params(w http.ResponseWriter, r *http.Request) {
+	var sh params.Sheet
+	if err := json.NewDecoder(r.Body).Decode(&sh); err != nil {
+		http.Error(w, fmt.Sprintf("invalid params.Sheet: %v", err), http.StatusBadRequest)
+		return
+	}
+	ss.Mu.Lock()
+	applied, err := ss.Net.ApplyParams(&sh, false)
+	ss.Mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]bool{"Applied": applied})
+}
+
+// Step handles POST /step, with an optional n query parameter for the
+// number of steps (1 by default), which must be between 1 and MaxSteps.
+func (ss *SimServer) Step(w http.ResponseWriter, r *http.Request) {
+	if ss.StepFunc == nil {
+		http.Error(w, "no StepFunc set for this simulation", http.StatusNotImplemented)
+		return
+	}
+	maxn := ss.MaxSteps
+	if maxn <= 0 {
+		maxn = DefMaxSteps
+	}
+	n := 1
+	if ns := r.URL.Query().Get("n"); ns != "" {
+		var err error
+		n, err = strconv.Atoi(ns)
+		if err != nil || n < 1 || n > maxn {
+			http.Error(w, fmt.Sprintf("invalid number of steps: %v -- must be between 1 and %d", ns, maxn), http.StatusBadRequest)
+			return
+		}
+	}
+	ss.Mu.Lock()
+	err := ss.StepFunc(n)
+	ss.Mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]int{"Steps": n})
+}
+
+// writeJSON writes given value as the JSON response.  An encoding error
+// is only logged, as part of the response may already have been written.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("server: error writing JSON response: %v\n", err)
+	}
+}
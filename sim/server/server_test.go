@@ -0,0 +1,145 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/internal/emertest"
+)
+
+func testServer() (*SimServer, *int) {
+	net := emertest.NewNetwork("Test")
+	net.AddLayer("In", []int{2, 3}, emer.Input)
+	hid := net.AddLayer("Hid", []int{2, 2}, emer.Hidden)
+	net.Build()
+	copy(hid.Vals["Act"], []float32{.1, .2, .3, .4})
+	steps := 0
+	ss := NewSimServer(net, func(n int) error {
+		steps += n
+		return nil
+	})
+	ss.MaxSteps = 10
+	return ss, &steps
+}
+
+func TestStatus(t *testing.T) {
+	ss, _ := testServer()
+	rec := httptest.NewRecorder()
+	ss.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/layers", nil))
+	var lis []LayerInfo
+	if err := json.NewDecoder(rec.Body).Decode(&lis); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET /layers: %d %v", rec.Code, err)
+	}
+	if len(lis) != 2 || lis[1].Name != "Hid" || lis[1].Type != "Hidden" || len(lis[1].Shape) != 2 {
+		t.Errorf("GET /layers: %+v", lis)
+	}
+
+	rec = httptest.NewRecorder()
+	ss.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/layers/Hid/activations", nil))
+	var lv LayerVals
+	if err := json.NewDecoder(rec.Body).Decode(&lv); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET activations: %d %v", rec.Code, err)
+	}
+	if lv.Var != "Act" || len(lv.Vals) != 4 || lv.Vals[3] != .4 {
+		t.Errorf("GET activations: %+v", lv)
+	}
+}
+
+func TestParams(t *testing.T) {
+	ss, _ := testServer()
+	net := ss.Net.(*emertest.Network)
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ss.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/params", strings.NewReader(body)))
+		return rec
+	}
+	rec := post(`[{"Sel": "#Hid", "Params": {"Layer.Thr": "7"}}]`)
+	var res map[string]bool
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("POST /params: %d %v", rec.Code, err)
+	}
+	if len(res) != 1 || !res["Applied"] {
+		t.Errorf("POST /params: %v, want Applied: true", res)
+	}
+	if net.Lays[1].Thr != 7 || net.Lays[0].Thr != 0 {
+		t.Errorf("POST /params: Thr In: %d Hid: %d, want 0, 7", net.Lays[0].Thr, net.Lays[1].Thr)
+	}
+
+	rec = post(`[{"Sel": "#Nope", "Params": {"Layer.Thr": "3"}}]`)
+	res = nil
+	if err := json.NewDecoder(rec.Body).Decode(&res); err != nil || rec.Code != http.StatusOK || res["Applied"] {
+		t.Errorf("POST /params with no match: %d %v %v", rec.Code, res, err)
+	}
+	if net.Lays[1].Thr != 7 {
+		t.Errorf("POST /params with no match changed Thr: %d", net.Lays[1].Thr)
+	}
+
+	rec = post(`[{"Sel": "#Hid", "Params": {"Layer.Nope": "3"}}]`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /params with invalid path: %d", rec.Code)
+	}
+}
+
+func TestStep(t *testing.T) {
+	ss, steps := testServer()
+	rec := httptest.NewRecorder()
+	ss.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/step?n=5", nil))
+	if rec.Code != http.StatusOK || *steps != 5 {
+		t.Errorf("POST /step?n=5: %d steps: %d", rec.Code, *steps)
+	}
+	rec = httptest.NewRecorder()
+	ss.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/step", nil))
+	if rec.Code != http.StatusOK || *steps != 6 {
+		t.Errorf("POST /step: %d steps: %d", rec.Code, *steps)
+	}
+	rec = httptest.NewRecorder()
+	ss.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/step?n=10", nil))
+	if rec.Code != http.StatusOK || *steps != 16 {
+		t.Errorf("POST /step?n=MaxSteps: %d steps: %d", rec.Code, *steps)
+	}
+}
+
+func TestBadInput(t *testing.T) {
+	ss, steps := testServer()
+	tests := []struct {
+		method, url, body string
+		code              int
+	}{
+		{http.MethodPost, "/step?n=11", "", http.StatusBadRequest},
+		{http.MethodPost, "/step?n=1000000000", "", http.StatusBadRequest},
+		{http.MethodPost, "/step?n=0", "", http.StatusBadRequest},
+		{http.MethodPost, "/step?n=-3", "", http.StatusBadRequest},
+		{http.MethodPost, "/step?n=abc", "", http.StatusBadRequest},
+		{http.MethodGet, "/step", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/layers", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/layers/Nope/activations", "", http.StatusNotFound},
+		{http.MethodGet, "/layers/Hid/activations?var=Nope", "", http.StatusBadRequest},
+		{http.MethodPost, "/params", "not json", http.StatusBadRequest},
+		{http.MethodGet, "/nope", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		ss.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body)))
+		if rec.Code != tt.code {
+			t.Errorf("%s %s: got %d, want %d", tt.method, tt.url, rec.Code, tt.code)
+		}
+	}
+	if *steps != 0 {
+		t.Errorf("rejected requests ran %d steps", *steps)
+	}
+
+	ss.StepFunc = nil
+	rec := httptest.NewRecorder()
+	ss.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/step", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("POST /step without StepFunc: %d", rec.Code)
+	}
+}